@@ -2,20 +2,32 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
-	"strconv"
+	"reflect"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/tkc/go-json-server/src/admin"
+	"github.com/tkc/go-json-server/src/certs"
 	"github.com/tkc/go-json-server/src/config"
 	"github.com/tkc/go-json-server/src/handler"
+	"github.com/tkc/go-json-server/src/health"
+	"github.com/tkc/go-json-server/src/journal"
+	"github.com/tkc/go-json-server/src/listen"
 	"github.com/tkc/go-json-server/src/logger"
+	"github.com/tkc/go-json-server/src/metrics"
 	"github.com/tkc/go-json-server/src/middleware"
+	"github.com/tkc/go-json-server/src/oidc"
+	"github.com/tkc/go-json-server/src/tracing"
+	"github.com/tkc/go-json-server/src/ui"
 )
 
 var (
@@ -25,45 +37,39 @@ var (
 	// Command line flags
 	configPath = flag.String("config", "./api.json", "Path to the configuration file")
 	port       = flag.Int("port", 0, "Server port (overrides config)")
+	host       = flag.String("host", "", "Server host or IP to bind (overrides config)")
+	listenOn   = flag.String("listen", "", "Comma-separated listen addresses, host:port or unix:/path (overrides config)")
 	logLevel   = flag.String("log-level", "", "Log level: debug, info, warn, error, fatal (overrides config)")
 	logFormat  = flag.String("log-format", "", "Log format: text, json (overrides config)")
 	logPath    = flag.String("log-path", "", "Path to log file (overrides config)")
-	cacheTTL   = flag.Int("cache-ttl", 300, "Cache TTL in seconds")
+	cacheTTL   = flag.Int("cache-ttl", 300, "Cache TTL in seconds when the config sets none")
+	adminAPI   = flag.Bool("admin", false, "Enable the runtime admin API (overrides config)")
+	adminPort  = flag.Int("admin-port", 0, "Serve the admin API on a separate port (overrides config)")
+	persist    = flag.Bool("admin-persist", false, "Persist admin API changes to the config file (overrides config)")
+	metricsOn  = flag.Bool("metrics", false, "Expose Prometheus metrics (overrides config)")
+	traceFile  = flag.String("trace-file", "", "Export spans as OTLP/JSON to this file (enables tracing)")
+	traceURL   = flag.String("trace-collector", "", "Export spans as OTLP/JSON to this collector URL (enables tracing)")
+	tlsCert    = flag.String("tls-cert", "", "TLS certificate file (overrides config)")
+	tlsKey     = flag.String("tls-key", "", "TLS private key file (overrides config)")
+	tlsAuto    = flag.Bool("tls-auto", false, "Serve HTTPS with a generated certificate signed by a cached local CA (overrides config)")
+	tlsHosts   = flag.String("tls-hosts", "", "Comma-separated hostnames and IPs for --tls-auto (overrides config)")
+	httpPort   = flag.Int("http-port", 0, "Also serve plain HTTP on this port when TLS is enabled (overrides config)")
+	clientAuth = flag.String("tls-client-auth", "", "Client certificate mode: request, require (overrides config)")
+	clientCA   = flag.String("tls-client-ca", "", "CA bundle verifying client certificates (overrides config)")
 )
 
 func main() {
 	// Parse command line flags
 	flag.Parse()
 
-	// Load configuration
-	cfg, err := config.LoadConfig(*configPath)
+	// Load configuration; command line flags override the file, also after a reload
+	cfg, err := config.LoadConfigWithOverrides(*configPath, applyFlags)
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
-	// Override configuration with command line flags
-	if *port > 0 {
-		cfg.Port = *port
-	}
-	if *logLevel != "" {
-		cfg.LogLevel = *logLevel
-	}
-	if *logFormat != "" {
-		cfg.LogFormat = *logFormat
-	}
-	if *logPath != "" {
-		cfg.LogPath = *logPath
-	}
-
 	// Initialize logger
-	logConfig := logger.LogConfig{
-		Level:      logger.ParseLogLevel(cfg.LogLevel),
-		Format:     logger.LogFormat(cfg.LogFormat),
-		OutputPath: cfg.LogPath,
-		TimeFormat: time.RFC3339,
-	}
-
-	log, err := logger.NewLogger(logConfig)
+	log, err := logger.NewLogger(logConfig(cfg))
 	if err != nil {
 		fmt.Printf("Failed to initialize logger: %v\n", err)
 		os.Exit(1)
@@ -76,43 +82,292 @@ func main() {
 		"port":    cfg.Port,
 	})
 
+	// Resolve the certificate to serve HTTPS with; TLS settings apply until a restart
+	tlsSettings := cfg.GetTLS()
+	certFile, keyFile := tlsSettings.CertFile, tlsSettings.KeyFile
+	if tlsSettings.Auto {
+		bundle, err := certs.EnsureAuto(tlsSettings.CacheDir, tlsSettings.Hosts)
+		if err != nil {
+			log.Fatal("Failed to generate TLS certificate", map[string]any{"error": err.Error()})
+		}
+		certFile, keyFile = bundle.CertFile, bundle.KeyFile
+
+		message := "Using cached local CA; trust it to avoid certificate warnings"
+		if bundle.CACreated {
+			message = "Generated local CA; trust it to avoid certificate warnings"
+		}
+		log.Info(message, map[string]any{"ca": bundle.CAFile, "cert": bundle.CertFile})
+	}
+
+	// Verify client certificates against the configured CA bundle
+	var tlsConfig *tls.Config
+	if tlsSettings.ClientAuth != "" {
+		clientCAs, err := certs.LoadCertPool(tlsSettings.ClientCAFile)
+		if err != nil {
+			log.Fatal("Failed to load client CA bundle", map[string]any{"error": err.Error()})
+		}
+		tlsConfig = &tls.Config{ClientCAs: clientCAs, ClientAuth: tls.VerifyClientCertIfGiven}
+		if tlsSettings.ClientAuth == config.ClientAuthRequire {
+			tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+		log.Info("Client certificate authentication enabled", map[string]any{
+			"mode": tlsSettings.ClientAuth,
+			"ca":   tlsSettings.ClientCAFile,
+		})
+	}
+
 	// Create server with response cache
 	server := handler.NewServer(cfg, log, time.Duration(*cacheTTL)*time.Second)
 
 	// Setup configuration hot-reloading
 	reloadCh := make(chan bool)
-	if err := config.WatchConfig(*configPath, cfg, reloadCh); err != nil {
+	if err := config.WatchConfig(*configPath, cfg, reloadCh, log); err != nil {
 		log.Error("Failed to watch config file", map[string]any{"error": err.Error()})
 	}
 
+	// Edits to response files only invalidate the responses rendered from them
+	responseFiles, err := config.WatchResponseFiles(cfg, log, server.InvalidateFile)
+	if err != nil {
+		log.Error("Failed to watch response files", map[string]any{"error": err.Error()})
+	}
+	refreshResponseFiles := func() {
+		if responseFiles == nil {
+			return
+		}
+		if err := responseFiles.Refresh(); err != nil {
+			log.Error("Failed to watch response files", map[string]any{"error": err.Error()})
+		}
+	}
+
+	// Route built-in services before the user-defined endpoints
+	mux := http.NewServeMux()
+	if cfg.OIDC.Enabled {
+		provider, err := oidc.NewProvider(cfg, log)
+		if err != nil {
+			log.Fatal("Failed to initialize OIDC provider", map[string]any{"error": err.Error()})
+		}
+		mux.Handle(cfg.OIDC.PathPrefix+"/", provider)
+		server.TokenVerifier = provider
+		log.Info("OIDC provider enabled", map[string]any{"pathPrefix": cfg.OIDC.PathPrefix})
+	}
+
+	// Expose Prometheus metrics
+	var serverMetrics *metrics.Metrics
+	if cfg.Metrics.Enabled {
+		serverMetrics = metrics.New()
+		server.Metrics = serverMetrics
+		server.Cache.RegisterMetrics(serverMetrics.Registry)
+		mux.Handle(cfg.Metrics.Path, serverMetrics.Registry.Handler())
+		log.Info("Metrics enabled", map[string]any{"path": cfg.Metrics.Path})
+	}
+
+	// Trace requests and export the spans
+	var tracer *tracing.Tracer
+	if cfg.Tracing.Enabled {
+		var exporters tracing.MultiExporter
+		if cfg.Tracing.File != "" {
+			fileExporter, err := tracing.NewFileExporter(cfg.Tracing.File)
+			if err != nil {
+				log.Fatal("Failed to initialize trace exporter", map[string]any{"error": err.Error()})
+			}
+			exporters = append(exporters, fileExporter)
+		}
+		if cfg.Tracing.CollectorURL != "" {
+			exporters = append(exporters, tracing.NewHTTPExporter(cfg.Tracing.CollectorURL, cfg.Tracing.Headers))
+		}
+
+		var exporter tracing.Exporter
+		if len(exporters) > 0 {
+			exporter = exporters
+		}
+		tracer = tracing.NewTracer(cfg.Tracing.ServiceName, exporter)
+		tracer.OnError = func(err error) {
+			log.Warn("Failed to export spans", map[string]any{"error": err.Error()})
+		}
+		log.Info("Tracing enabled", map[string]any{
+			"serviceName":  tracer.ServiceName,
+			"file":         cfg.Tracing.File,
+			"collectorURL": cfg.Tracing.CollectorURL,
+		})
+	}
+
+	// Sockets passed by systemd socket activation take precedence over the
+	// configured listeners, which in turn replace the default host:port
+	systemdListeners, err := listen.Systemd()
+	if err != nil {
+		log.Fatal("Failed to use systemd sockets", map[string]any{"error": err.Error()})
+	}
+
+	// Channel to listen for errors coming from the listeners
+	serverErrors := make(chan error, len(systemdListeners)+len(cfg.ListenAddresses())+2)
+
+	// Mount the admin API on the main port or serve it on its own port
+	var adminSrv *http.Server
+	var adminGroup *listen.Group
+	var requestJournal *journal.Journal
+	adminSettings := cfg.GetAdmin()
+	if adminSettings.Enabled {
+		requestJournal = journal.New(cfg.Journal.Capacity)
+
+		adminHandler := admin.New(cfg, log, *configPath, adminSettings.Persist)
+		adminHandler.Journal = requestJournal
+		adminHandler.Cache = server.Cache
+		adminHandler.OnChange = func() {
+			server.ClearCache()
+			refreshResponseFiles()
+		}
+
+		// The web UI is served next to the admin API it talks to
+		adminMux := mux
+		if adminSettings.Port != 0 {
+			adminMux = http.NewServeMux()
+		}
+		adminMux.Handle(admin.PathPrefix+"/", adminHandler)
+		adminMux.Handle(ui.PathPrefix, ui.Handler())
+		adminMux.Handle(ui.PathPrefix+"/", ui.Handler())
+
+		if adminSettings.Port == 0 {
+			log.Info("Admin API enabled", map[string]any{"path": admin.PathPrefix, "ui": ui.PathPrefix})
+		} else {
+			adminSrv = &http.Server{
+				Handler: middleware.Chain(
+					middleware.RequestID(),
+					middleware.Logger(log),
+					middleware.Recovery(log),
+				)(adminMux),
+			}
+			adminGroup = listen.NewGroup(adminSrv, serverErrors)
+			adminGroup.OnServe = func(l net.Listener) {
+				log.Info("Admin API listening", map[string]any{"address": l.Addr().String(), "path": admin.PathPrefix, "ui": ui.PathPrefix})
+			}
+			adminGroup.OnClose = logClosed(log)
+			if err := adminGroup.Rebind(adminSpecs(cfg)); err != nil {
+				log.Fatal("Failed to listen", map[string]any{"error": err.Error()})
+			}
+		}
+	}
+	mux.HandleFunc("/", server.HandleRequest)
+
 	// Create HTTP server with middlewares
+	middlewares := []middleware.Middleware{
+		middleware.RequestID(),
+	}
+	if tracer != nil {
+		middlewares = append(middlewares, middleware.Tracing(tracer))
+	}
+	if serverMetrics != nil {
+		middlewares = append(middlewares, middleware.Metrics(serverMetrics))
+	}
+	middlewares = append(middlewares, middleware.Logger(log))
+	if requestJournal != nil {
+		middlewares = append(middlewares, middleware.Journal(requestJournal, admin.PathPrefix, ui.PathPrefix))
+	}
+	middlewares = append(middlewares,
+		// The admin API and UI are never shared with other origins
+		middleware.CORSPolicy(server.ResolveCORS, admin.PathPrefix, ui.PathPrefix),
+		middleware.DynamicRateLimit(server.ResolveRateLimiter),
+		middleware.Timeout(30*time.Second),
+		middleware.Recovery(log),
+	)
+
+	// Probes bypass the middlewares so that rate limits and timeouts never fail them
+	checker := health.New(cfg, Version, *configPath)
+	root := http.NewServeMux()
+	for _, path := range health.Paths {
+		root.Handle(path, checker)
+	}
+	root.Handle("/", middleware.Chain(middlewares...)(mux))
+
 	srv := &http.Server{
-		Addr: ":" + strconv.Itoa(cfg.Port),
-		Handler: middleware.Chain(
-			middleware.RequestID(),
-			middleware.Logger(log),
-			middleware.CORS(),
-			middleware.Timeout(30*time.Second),
-			middleware.Recovery(log),
-		)(http.HandlerFunc(server.HandleRequest)),
+		Handler:   root,
+		TLSConfig: tlsConfig,
 	}
 
-	// Channel to listen for errors coming from the listener
-	serverErrors := make(chan error, 1)
+	// Config and response files were loaded and validated above
+	checker.SetReady(true, "")
 
-	// Start the server
-	go func() {
-		log.Info("Server listening", map[string]any{"address": srv.Addr})
-		serverErrors <- srv.ListenAndServe()
-	}()
+	// Start the server on every listener
+	serverGroup := listen.NewGroup(srv, serverErrors)
+	if tlsSettings.Enabled() {
+		serverGroup.CertFile, serverGroup.KeyFile = certFile, keyFile
+	}
+	serverGroup.OnServe = func(l net.Listener) {
+		fields := map[string]any{"address": l.Addr().String(), "network": l.Addr().Network()}
+		if tlsSettings.Enabled() {
+			fields["tls"] = true
+		}
+		log.Info("Server listening", fields)
+	}
+	serverGroup.OnClose = logClosed(log)
+	if systemdListeners != nil {
+		for _, l := range systemdListeners {
+			serverGroup.Serve(l.Addr().String(), l)
+		}
+	} else if err := serverGroup.Rebind(listenSpecs(cfg)); err != nil {
+		log.Fatal("Failed to listen", map[string]any{"error": err.Error()})
+	}
+
+	// Optionally keep serving plain HTTP next to HTTPS
+	var httpSrv *http.Server
+	var httpGroup *listen.Group
+	if tlsSettings.Enabled() && tlsSettings.HTTPPort != 0 {
+		httpSrv = &http.Server{Handler: root}
+		httpGroup = listen.NewGroup(httpSrv, serverErrors)
+		httpGroup.OnServe = func(l net.Listener) {
+			log.Info("Server listening", map[string]any{"address": l.Addr().String()})
+		}
+		httpGroup.OnClose = logClosed(log)
+		if err := httpGroup.Rebind(httpSpecs(cfg)); err != nil {
+			log.Fatal("Failed to listen", map[string]any{"error": err.Error()})
+		}
+	}
 
 	// Channel to listen for config reload events
 	go func() {
-		for range reloadCh {
+		for ok := range reloadCh {
+			if serverMetrics != nil {
+				if ok {
+					serverMetrics.ConfigReloads.Inc("success")
+				} else {
+					serverMetrics.ConfigReloads.Inc("failure")
+				}
+			}
+			if !ok {
+				checker.SetReady(false, "configuration reload failed")
+				continue
+			}
+			checker.SetReady(true, "")
+
+			// Apply the logging settings before reporting the reload
+			if err := log.Reconfigure(logConfig(cfg)); err != nil {
+				log.Error("Failed to reconfigure logger", map[string]any{"error": err.Error()})
+			}
 			log.Info("Configuration reloaded")
+			if tlsRestartRequired(tlsSettings, cfg.GetTLS()) {
+				log.Warn("TLS settings changed; restart the server to apply them")
+			}
+
+			// Move to new addresses; requests in flight finish on the old listeners
+			if systemdListeners == nil {
+				if err := serverGroup.Rebind(listenSpecs(cfg)); err != nil {
+					log.Error("Failed to move listeners", map[string]any{"error": err.Error()})
+				}
+			}
+			if adminGroup != nil {
+				if err := adminGroup.Rebind(adminSpecs(cfg)); err != nil {
+					log.Error("Failed to move admin listener", map[string]any{"error": err.Error()})
+				}
+			}
+			if httpGroup != nil {
+				if err := httpGroup.Rebind(httpSpecs(cfg)); err != nil {
+					log.Error("Failed to move HTTP listener", map[string]any{"error": err.Error()})
+				}
+			}
 
-			// Clear the response cache when config changes
+			// Route new requests with the new endpoints and clear the response cache
+			server.Rebuild()
 			server.ClearCache()
+			refreshResponseFiles()
 		}
 	}()
 
@@ -141,5 +396,134 @@ func main() {
 			log.Error("Graceful shutdown failed", map[string]any{"error": err.Error()})
 			srv.Close()
 		}
+		if httpSrv != nil {
+			if err := httpSrv.Shutdown(ctx); err != nil {
+				httpSrv.Close()
+			}
+		}
+		if adminSrv != nil {
+			if err := adminSrv.Shutdown(ctx); err != nil {
+				adminSrv.Close()
+			}
+		}
+		if tracer != nil {
+			tracer.Shutdown(ctx)
+		}
+	}
+}
+
+// applyFlags overrides configuration with the command line flags that were set
+func applyFlags(cfg *config.Config) {
+	if *port > 0 {
+		cfg.Port = *port
+	}
+	if *host != "" {
+		cfg.Host = *host
+	}
+	if *listenOn != "" {
+		cfg.Listeners = nil
+		for _, address := range strings.Split(*listenOn, ",") {
+			cfg.Listeners = append(cfg.Listeners, config.ListenerConfig{Address: strings.TrimSpace(address)})
+		}
+	}
+	if *logLevel != "" {
+		cfg.LogLevel = *logLevel
+	}
+	if *logFormat != "" {
+		cfg.LogFormat = *logFormat
+	}
+	if *logPath != "" {
+		cfg.LogPath = *logPath
+	}
+	if *adminAPI {
+		cfg.Admin.Enabled = true
+	}
+	if *adminPort > 0 {
+		cfg.Admin.Port = *adminPort
+	}
+	if *persist {
+		cfg.Admin.Persist = true
+	}
+	if *metricsOn {
+		cfg.Metrics.Enabled = true
+	}
+	if *traceFile != "" {
+		cfg.Tracing.Enabled = true
+		cfg.Tracing.File = *traceFile
+	}
+	if *traceURL != "" {
+		cfg.Tracing.Enabled = true
+		cfg.Tracing.CollectorURL = *traceURL
+	}
+	if *tlsCert != "" || *tlsKey != "" {
+		cfg.TLS.CertFile = *tlsCert
+		cfg.TLS.KeyFile = *tlsKey
+		cfg.TLS.Auto = false
+	}
+	if *tlsAuto {
+		cfg.TLS.Auto = true
+		cfg.TLS.CertFile = ""
+		cfg.TLS.KeyFile = ""
+	}
+	if *tlsHosts != "" {
+		cfg.TLS.Hosts = strings.Split(*tlsHosts, ",")
+	}
+	if *httpPort > 0 {
+		cfg.TLS.HTTPPort = *httpPort
+	}
+	if *clientAuth != "" {
+		cfg.TLS.ClientAuth = *clientAuth
+	}
+	if *clientCA != "" {
+		cfg.TLS.ClientCAFile = *clientCA
+	}
+}
+
+// logConfig derives the logger settings from the configuration
+func logConfig(cfg *config.Config) logger.LogConfig {
+	level, format, path := cfg.GetLogConfig()
+	return logger.LogConfig{
+		Level:      logger.ParseLogLevel(level),
+		Format:     logger.LogFormat(format),
+		OutputPath: path,
+		TimeFormat: time.RFC3339,
+	}
+}
+
+// listenSpecs returns the listeners the server should accept connections on
+func listenSpecs(cfg *config.Config) []listen.Spec {
+	var specs []listen.Spec
+	for _, lc := range cfg.ListenAddresses() {
+		// Modes were checked when the configuration was validated
+		mode, _ := lc.FileMode()
+		specs = append(specs, listen.Spec{Address: lc.Address, Mode: mode})
+	}
+	return specs
+}
+
+// adminSpecs returns the listener of the separate admin API port
+func adminSpecs(cfg *config.Config) []listen.Spec {
+	return []listen.Spec{{Address: listen.Address(cfg.GetHost(), cfg.GetAdmin().Port)}}
+}
+
+// httpSpecs returns the listener of the plain HTTP port served next to HTTPS
+func httpSpecs(cfg *config.Config) []listen.Spec {
+	return []listen.Spec{{Address: listen.Address(cfg.GetHost(), cfg.GetTLS().HTTPPort)}}
+}
+
+// tlsRestartRequired reports whether TLS settings changed in a way that only a
+// restart applies. Moving the plain HTTP port is applied by rebinding it.
+func tlsRestartRequired(started, current config.TLSConfig) bool {
+	if (started.HTTPPort == 0) != (current.HTTPPort == 0) {
+		return true
+	}
+	started.HTTPPort, current.HTTPPort = 0, 0
+	return !reflect.DeepEqual(started, current)
+}
+
+// logClosed logs listeners that were closed after moving to a new address
+func logClosed(log *logger.Logger) func(net.Listener) {
+	return func(l net.Listener) {
+		log.Info("Listener closed", map[string]any{"address": l.Addr().String()})
 	}
 }
@@ -61,3 +61,22 @@ func TestMainComponents(t *testing.T) {
 	err = cfg.Validate()
 	assert.NoError(t, err)
 }
+
+func TestTLSRestartRequired(t *testing.T) {
+	started := config.TLSConfig{Auto: true, Hosts: []string{"localhost"}, HTTPPort: 8080}
+
+	// Moving the plain HTTP port is applied by rebinding it
+	moved := started
+	moved.HTTPPort = 8081
+	assert.False(t, tlsRestartRequired(started, moved))
+
+	// Turning the plain HTTP port on or off needs a restart
+	off := started
+	off.HTTPPort = 0
+	assert.True(t, tlsRestartRequired(started, off))
+
+	// So do certificate changes
+	hosts := started
+	hosts.Hosts = []string{"localhost", "example.test"}
+	assert.True(t, tlsRestartRequired(started, hosts))
+}
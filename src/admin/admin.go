@@ -0,0 +1,239 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+
+	"github.com/tkc/go-json-server/src/config"
+	"github.com/tkc/go-json-server/src/handler"
+	"github.com/tkc/go-json-server/src/internal/httputil"
+	"github.com/tkc/go-json-server/src/journal"
+	"github.com/tkc/go-json-server/src/logger"
+)
+
+// PathPrefix is the path under which the admin API is served
+const PathPrefix = "/__admin"
+
+// Admin serves the runtime admin REST API
+type Admin struct {
+	Config     *config.Config
+	Logger     *logger.Logger
+	ConfigPath string
+	Persist    bool
+
+	// Journal, when set, is exposed for querying and verification
+	Journal *journal.Journal
+
+	// Cache, when set, is exposed for inspection and clearing
+	Cache *handler.ResponseCache
+
+	// OnChange is called after every successful change, e.g. to clear caches
+	OnChange func()
+
+	mux *http.ServeMux
+}
+
+// New creates the admin API for a configuration.
+// When persist is set, endpoint changes are written back to configPath.
+// Response files the API serves or changes must be in the directory of configPath.
+func New(cfg *config.Config, log *logger.Logger, configPath string, persist bool) *Admin {
+	a := &Admin{
+		Config:     cfg,
+		Logger:     log,
+		ConfigPath: configPath,
+		Persist:    persist,
+		mux:        http.NewServeMux(),
+	}
+	if persist && configPath != "" {
+		cfg.SetPersistPath(configPath)
+	}
+
+	a.mux.HandleFunc("GET "+PathPrefix+"/endpoints", a.listEndpoints)
+	a.mux.HandleFunc("POST "+PathPrefix+"/endpoints", a.createEndpoint)
+	a.mux.HandleFunc("GET "+PathPrefix+"/endpoints/{id}", a.getEndpoint)
+	a.mux.HandleFunc("PUT "+PathPrefix+"/endpoints/{id}", a.updateEndpoint)
+	a.mux.HandleFunc("DELETE "+PathPrefix+"/endpoints/{id}", a.deleteEndpoint)
+	a.mux.HandleFunc("POST "+PathPrefix+"/endpoints/{id}/enable", a.enableEndpoint)
+	a.mux.HandleFunc("POST "+PathPrefix+"/endpoints/{id}/disable", a.disableEndpoint)
+	a.mux.HandleFunc("POST "+PathPrefix+"/endpoints/{id}/variant", a.selectVariant)
+	a.mux.HandleFunc("GET "+PathPrefix+"/endpoints/{id}/response", a.getResponseFile)
+	a.mux.HandleFunc("PUT "+PathPrefix+"/endpoints/{id}/response", a.updateResponseFile)
+	a.mux.HandleFunc("GET "+PathPrefix+"/requests", a.listRequests)
+	a.mux.HandleFunc("DELETE "+PathPrefix+"/requests", a.clearRequests)
+	a.mux.HandleFunc("POST "+PathPrefix+"/requests/verify", a.verifyRequests)
+	a.mux.HandleFunc("GET "+PathPrefix+"/cache", a.getCache)
+	a.mux.HandleFunc("DELETE "+PathPrefix+"/cache", a.clearCache)
+
+	return a
+}
+
+// errNotJSON is returned for changes that are not sent as JSON
+var errNotJSON = errors.New("changes must be sent as application/json")
+
+// ServeHTTP dispatches admin API requests. Changes must be sent as JSON, which
+// browsers only send cross-origin after a preflight the admin API never allows.
+func (a *Admin) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+		if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+			writeError(w, http.StatusUnsupportedMediaType, errNotJSON)
+			return
+		}
+	}
+	a.mux.ServeHTTP(w, r)
+}
+
+// listEndpoints returns all configured endpoints
+func (a *Admin) listEndpoints(w http.ResponseWriter, r *http.Request) {
+	httputil.WriteJSON(w, http.StatusOK, a.Config.GetEndpoints())
+}
+
+// getEndpoint returns a single endpoint
+func (a *Admin) getEndpoint(w http.ResponseWriter, r *http.Request) {
+	ep, ok := a.Config.GetEndpoint(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, config.ErrEndpointNotFound)
+		return
+	}
+	httputil.WriteJSON(w, http.StatusOK, ep)
+}
+
+// createEndpoint adds a new endpoint
+func (a *Admin) createEndpoint(w http.ResponseWriter, r *http.Request) {
+	var ep config.Endpoint
+	if err := json.NewDecoder(r.Body).Decode(&ep); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := a.checkEndpointFiles(ep); err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+
+	created, err := a.Config.AddEndpoint(ep)
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+
+	a.changed("Endpoint created", created)
+	httputil.WriteJSON(w, http.StatusCreated, created)
+}
+
+// updateEndpoint replaces an existing endpoint
+func (a *Admin) updateEndpoint(w http.ResponseWriter, r *http.Request) {
+	var ep config.Endpoint
+	if err := json.NewDecoder(r.Body).Decode(&ep); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := a.checkEndpointFiles(ep); err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+
+	updated, err := a.Config.UpdateEndpoint(r.PathValue("id"), ep)
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+
+	a.changed("Endpoint updated", updated)
+	httputil.WriteJSON(w, http.StatusOK, updated)
+}
+
+// deleteEndpoint removes an endpoint
+func (a *Admin) deleteEndpoint(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	ep, _ := a.Config.GetEndpoint(id)
+
+	if err := a.Config.DeleteEndpoint(id); err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+
+	a.changed("Endpoint deleted", ep)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// enableEndpoint re-enables a disabled endpoint
+func (a *Admin) enableEndpoint(w http.ResponseWriter, r *http.Request) {
+	a.setDisabled(w, r, false)
+}
+
+// disableEndpoint disables an endpoint without removing it
+func (a *Admin) disableEndpoint(w http.ResponseWriter, r *http.Request) {
+	a.setDisabled(w, r, true)
+}
+
+// setDisabled toggles the disabled flag of an endpoint
+func (a *Admin) setDisabled(w http.ResponseWriter, r *http.Request, disabled bool) {
+	ep, err := a.Config.SetEndpointDisabled(r.PathValue("id"), disabled)
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+
+	message := "Endpoint enabled"
+	if disabled {
+		message = "Endpoint disabled"
+	}
+	a.changed(message, ep)
+	httputil.WriteJSON(w, http.StatusOK, ep)
+}
+
+// selectVariant switches the response variant served by an endpoint
+func (a *Admin) selectVariant(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Variant string `json:"variant"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ep, err := a.Config.SetEndpointVariant(r.PathValue("id"), body.Variant)
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+
+	a.changed("Endpoint variant selected", ep)
+	httputil.WriteJSON(w, http.StatusOK, ep)
+}
+
+// changed logs a change and notifies listeners
+func (a *Admin) changed(message string, ep config.Endpoint) {
+	a.Logger.Info(message, map[string]any{
+		"id":     ep.ID,
+		"method": ep.Method,
+		"path":   ep.Path,
+	})
+
+	if a.OnChange != nil {
+		a.OnChange()
+	}
+}
+
+// statusFor maps configuration errors to HTTP status codes
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, config.ErrEndpointNotFound), errors.Is(err, config.ErrVariantNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, config.ErrDuplicateEndpoint):
+		return http.StatusConflict
+	case errors.Is(err, errOutsideConfigDir):
+		return http.StatusForbidden
+	case errors.Is(err, config.ErrPersist):
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// writeError writes a JSON error response
+func writeError(w http.ResponseWriter, status int, err error) {
+	httputil.WriteJSON(w, status, map[string]string{"error": err.Error()})
+}
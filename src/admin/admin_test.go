@@ -0,0 +1,298 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tkc/go-json-server/src/config"
+	"github.com/tkc/go-json-server/src/handler"
+	"github.com/tkc/go-json-server/src/journal"
+	"github.com/tkc/go-json-server/src/logger"
+)
+
+func newTestAdmin(t *testing.T) (*Admin, string) {
+	tempDir := t.TempDir()
+
+	jsonFile := filepath.Join(tempDir, "test.json")
+	err := os.WriteFile(jsonFile, []byte(`{"message":"test"}`), 0644)
+	assert.NoError(t, err)
+
+	cfg := &config.Config{
+		Endpoints: []config.Endpoint{
+			{ID: "users", Method: "GET", Path: "/users", JsonPath: jsonFile, Status: 200},
+		},
+	}
+
+	log, err := logger.NewLogger(logger.LogConfig{Level: logger.LevelError})
+	assert.NoError(t, err)
+
+	return New(cfg, log, filepath.Join(tempDir, "api.json"), false), jsonFile
+}
+
+func doAdmin(a *Admin, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, req)
+	return w
+}
+
+func TestAdmin_Endpoints(t *testing.T) {
+	a, jsonFile := newTestAdmin(t)
+
+	changes := 0
+	a.OnChange = func() { changes++ }
+
+	// List
+	w := doAdmin(a, "GET", "/__admin/endpoints", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var endpoints []config.Endpoint
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &endpoints))
+	assert.Len(t, endpoints, 1)
+
+	// Create
+	body := `{"method":"POST","status":201,"path":"/users","jsonPath":"` + jsonFile + `"}`
+	w = doAdmin(a, "POST", "/__admin/endpoints", body)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created config.Endpoint
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.NotEmpty(t, created.ID)
+
+	// Duplicates conflict
+	w = doAdmin(a, "POST", "/__admin/endpoints", body)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Get
+	w = doAdmin(a, "GET", "/__admin/endpoints/"+created.ID, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Update
+	w = doAdmin(a, "PUT", "/__admin/endpoints/"+created.ID, `{"method":"POST","status":202,"path":"/users","jsonPath":"`+jsonFile+`"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	ep, _ := a.Config.GetEndpoint(created.ID)
+	assert.Equal(t, 202, ep.Status)
+
+	// Disable and enable
+	w = doAdmin(a, "POST", "/__admin/endpoints/users/disable", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	ep, _ = a.Config.GetEndpoint("users")
+	assert.True(t, ep.Disabled)
+
+	w = doAdmin(a, "POST", "/__admin/endpoints/users/enable", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	ep, _ = a.Config.GetEndpoint("users")
+	assert.False(t, ep.Disabled)
+
+	// Delete
+	w = doAdmin(a, "DELETE", "/__admin/endpoints/"+created.ID, "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = doAdmin(a, "GET", "/__admin/endpoints/"+created.ID, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	assert.Equal(t, 5, changes)
+}
+
+func TestAdmin_InvalidRequests(t *testing.T) {
+	a, _ := newTestAdmin(t)
+
+	w := doAdmin(a, "POST", "/__admin/endpoints", "not json")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doAdmin(a, "POST", "/__admin/endpoints", `{"method":"GET","path":""}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doAdmin(a, "DELETE", "/__admin/endpoints/missing", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAdmin_UnsafeChanges(t *testing.T) {
+	a, jsonFile := newTestAdmin(t)
+	outside := filepath.Join(t.TempDir(), "secret.txt")
+	assert.NoError(t, os.WriteFile(outside, []byte("secret"), 0644))
+
+	// Changes sent as anything but JSON are refused, so browsers cannot send them cross-origin
+	for _, contentType := range []string{"text/plain", "application/x-www-form-urlencoded", ""} {
+		req := httptest.NewRequest("POST", "/__admin/endpoints/users/disable", strings.NewReader("{}"))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		w := httptest.NewRecorder()
+		a.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code, contentType)
+	}
+	ep, _ := a.Config.GetEndpoint("users")
+	assert.False(t, ep.Disabled)
+
+	// Parameters of the JSON media type are fine
+	req := httptest.NewRequest("POST", "/__admin/endpoints/users/disable", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Response files must be in the config directory
+	for _, body := range []string{
+		`{"method":"GET","status":200,"path":"/x","jsonPath":"` + outside + `"}`,
+		`{"method":"GET","status":200,"path":"/x","jsonPath":"` + filepath.Dir(jsonFile) + `/../secret.txt"}`,
+		`{"method":"GET","status":200,"path":"/x","jsonPath":"` + jsonFile + `","variants":{"leak":{"jsonPath":"` + outside + `"}}}`,
+		`{"method":"GET","status":200,"path":"/x","jsonPath":"` + jsonFile + `","locales":{"en":"` + outside + `"}}`,
+		`{"path":"/files/","folder":"/"}`,
+	} {
+		w = doAdmin(a, "POST", "/__admin/endpoints", body)
+		assert.Equal(t, http.StatusForbidden, w.Code, body)
+	}
+	w = doAdmin(a, "PUT", "/__admin/endpoints/users", `{"method":"GET","status":200,"path":"/users","jsonPath":"`+outside+`"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Len(t, a.Config.GetEndpoints(), 1)
+
+	// Files of configured endpoints outside the directory are neither read nor written
+	_, err := a.Config.UpdateEndpoint("users", config.Endpoint{Method: "GET", Status: 200, Path: "/users", JsonPath: outside})
+	assert.NoError(t, err)
+	w = doAdmin(a, "GET", "/__admin/endpoints/users/response", "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.NotContains(t, w.Body.String(), `"content"`)
+	w = doAdmin(a, "PUT", "/__admin/endpoints/users/response", `{"content":"pwned"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	content, err := os.ReadFile(outside)
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", string(content))
+}
+
+func TestAdmin_Requests(t *testing.T) {
+	a, _ := newTestAdmin(t)
+
+	// Without a journal the endpoints are unavailable
+	w := doAdmin(a, "GET", "/__admin/requests", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	a.Journal = journal.New(10)
+	a.Journal.Record(journal.Entry{Method: "POST", Path: "/users", Body: `{"name":"alice"}`, Status: 201})
+	a.Journal.Record(journal.Entry{Method: "POST", Path: "/users", Body: `{"name":"bob"}`, Status: 201})
+	a.Journal.Record(journal.Entry{Method: "GET", Path: "/users", Status: 200})
+
+	// Query with filters
+	w = doAdmin(a, "GET", "/__admin/requests?method=POST&bodyContains=bob", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var entries []journal.Entry
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &entries))
+	assert.Len(t, entries, 1)
+
+	w = doAdmin(a, "GET", "/__admin/requests?status=abc", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Verify
+	w = doAdmin(a, "POST", "/__admin/requests/verify", `{"method":"POST","path":"/users","count":2}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var result journal.VerificationResult
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.True(t, result.Verified)
+	assert.Equal(t, 2, result.Count)
+
+	w = doAdmin(a, "POST", "/__admin/requests/verify", `{"method":"POST","bodyJSON":{"name":"carol"},"count":1}`)
+	assert.Equal(t, http.StatusExpectationFailed, w.Code)
+
+	// Clear
+	w = doAdmin(a, "DELETE", "/__admin/requests", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, a.Journal.Entries(journal.Filter{}))
+}
+
+func TestAdmin_Cache(t *testing.T) {
+	a, _ := newTestAdmin(t)
+
+	// Without a cache the endpoints are unavailable
+	w := doAdmin(a, "GET", "/__admin/cache", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	a.Cache = handler.NewResponseCache(10, 1<<20)
+	a.Cache.Set("GET:/users", "", []byte(`[]`), time.Minute)
+	a.Cache.Get("GET:/users")
+
+	w = doAdmin(a, "GET", "/__admin/cache", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var stats handler.CacheStats
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	assert.Equal(t, 1, stats.Entries)
+	assert.Equal(t, uint64(1), stats.Hits)
+	assert.Equal(t, 10, stats.MaxEntries)
+
+	w = doAdmin(a, "DELETE", "/__admin/cache", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, 0, a.Cache.Stats().Entries)
+}
+
+func TestAdmin_ResponseFilesAndVariants(t *testing.T) {
+	a, jsonFile := newTestAdmin(t)
+
+	errorFile := filepath.Join(filepath.Dir(jsonFile), "error.json")
+	err := os.WriteFile(errorFile, []byte(`{"error":"boom"}`), 0644)
+	assert.NoError(t, err)
+
+	_, err = a.Config.UpdateEndpoint("users", config.Endpoint{
+		Method:   "GET",
+		Path:     "/users",
+		JsonPath: jsonFile,
+		Status:   200,
+		Variants: map[string]config.ResponseVariant{
+			"error": {Status: 500, JsonPath: errorFile},
+		},
+	})
+	assert.NoError(t, err)
+
+	changes := 0
+	a.OnChange = func() { changes++ }
+
+	// Read the default response file
+	w := doAdmin(a, "GET", "/__admin/endpoints/users/response", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var file responseFile
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &file))
+	assert.Equal(t, jsonFile, file.Path)
+	assert.Equal(t, `{"message":"test"}`, file.Content)
+
+	// Read a variant's response file
+	w = doAdmin(a, "GET", "/__admin/endpoints/users/response?variant=error", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &file))
+	assert.Equal(t, errorFile, file.Path)
+
+	w = doAdmin(a, "GET", "/__admin/endpoints/users/response?variant=missing", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Invalid JSON is rejected
+	w = doAdmin(a, "PUT", "/__admin/endpoints/users/response", `{"content":"{broken"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Update the file
+	w = doAdmin(a, "PUT", "/__admin/endpoints/users/response", `{"content":"{\"message\":\"updated\"}"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	content, err := os.ReadFile(jsonFile)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"message":"updated"}`, string(content))
+
+	// Switch variants
+	w = doAdmin(a, "POST", "/__admin/endpoints/users/variant", `{"variant":"error"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	ep, _ := a.Config.GetEndpoint("users")
+	status, path := ep.ActiveResponse()
+	assert.Equal(t, 500, status)
+	assert.Equal(t, errorFile, path)
+
+	w = doAdmin(a, "POST", "/__admin/endpoints/users/variant", `{"variant":"missing"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = doAdmin(a, "POST", "/__admin/endpoints/users/variant", `{"variant":""}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	ep, _ = a.Config.GetEndpoint("users")
+	status, _ = ep.ActiveResponse()
+	assert.Equal(t, 200, status)
+
+	assert.Equal(t, 3, changes)
+}
@@ -0,0 +1,33 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/tkc/go-json-server/src/internal/httputil"
+)
+
+// errCacheUnavailable is returned when no response cache is attached
+var errCacheUnavailable = errors.New("response cache is not available")
+
+// getCache returns the response cache statistics
+func (a *Admin) getCache(w http.ResponseWriter, r *http.Request) {
+	if a.Cache == nil {
+		writeError(w, http.StatusNotFound, errCacheUnavailable)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, a.Cache.Stats())
+}
+
+// clearCache removes all cached responses
+func (a *Admin) clearCache(w http.ResponseWriter, r *http.Request) {
+	if a.Cache == nil {
+		writeError(w, http.StatusNotFound, errCacheUnavailable)
+		return
+	}
+
+	a.Cache.Clear()
+	a.Logger.InfoContext(r.Context(), "Response cache cleared")
+	w.WriteHeader(http.StatusNoContent)
+}
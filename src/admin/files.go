@@ -0,0 +1,60 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/tkc/go-json-server/src/config"
+)
+
+// errOutsideConfigDir is returned for files the admin API may not serve or change
+var errOutsideConfigDir = errors.New("file is outside the config directory")
+
+// configDir returns the absolute directory admin changes are confined to
+func (a *Admin) configDir() (string, error) {
+	dir := "."
+	if a.ConfigPath != "" {
+		dir = filepath.Dir(a.ConfigPath)
+	}
+	return filepath.Abs(dir)
+}
+
+// checkFile rejects paths outside the config directory
+func (a *Admin) checkFile(path string) error {
+	dir, err := a.configDir()
+	if err != nil {
+		return err
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(dir, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("%w: %s", errOutsideConfigDir, path)
+	}
+	return nil
+}
+
+// checkEndpointFiles rejects endpoints that serve files outside the config directory
+func (a *Admin) checkEndpointFiles(ep config.Endpoint) error {
+	paths := []string{ep.JsonPath, ep.Folder}
+	for _, variant := range ep.Variants {
+		paths = append(paths, variant.JsonPath)
+	}
+	for _, file := range ep.Locales {
+		paths = append(paths, file)
+	}
+
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		if err := a.checkFile(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tkc/go-json-server/src/internal/httputil"
+	"github.com/tkc/go-json-server/src/journal"
+)
+
+// errJournalDisabled is returned when the request journal is not enabled
+var errJournalDisabled = errors.New("request journal is not enabled")
+
+// listRequests returns recorded requests matching the query parameters
+func (a *Admin) listRequests(w http.ResponseWriter, r *http.Request) {
+	if a.Journal == nil {
+		writeError(w, http.StatusNotFound, errJournalDisabled)
+		return
+	}
+
+	filter, err := filterFromQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, a.Journal.Entries(filter))
+}
+
+// clearRequests empties the journal
+func (a *Admin) clearRequests(w http.ResponseWriter, r *http.Request) {
+	if a.Journal == nil {
+		writeError(w, http.StatusNotFound, errJournalDisabled)
+		return
+	}
+
+	a.Journal.Clear()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// verifyRequests checks an expectation against the journal, answering
+// 200 when it holds and 417 Expectation Failed when it does not
+func (a *Admin) verifyRequests(w http.ResponseWriter, r *http.Request) {
+	if a.Journal == nil {
+		writeError(w, http.StatusNotFound, errJournalDisabled)
+		return
+	}
+
+	var verification journal.Verification
+	if err := json.NewDecoder(r.Body).Decode(&verification); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := verification.Compile(); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	result := a.Journal.Verify(verification)
+	status := http.StatusOK
+	if !result.Verified {
+		status = http.StatusExpectationFailed
+	}
+	httputil.WriteJSON(w, status, result)
+}
+
+// filterFromQuery builds a journal filter from query parameters
+func filterFromQuery(r *http.Request) (journal.Filter, error) {
+	query := r.URL.Query()
+	filter := journal.Filter{
+		Method:       query.Get("method"),
+		Path:         query.Get("path"),
+		PathRegex:    query.Get("pathRegex"),
+		EndpointID:   query.Get("endpointId"),
+		Endpoint:     query.Get("endpoint"),
+		BodyContains: query.Get("bodyContains"),
+		BodyRegex:    query.Get("bodyRegex"),
+	}
+
+	if status := query.Get("status"); status != "" {
+		value, err := strconv.Atoi(status)
+		if err != nil {
+			return filter, fmt.Errorf("invalid status: %w", err)
+		}
+		filter.Status = value
+	}
+	if since := query.Get("since"); since != "" {
+		value, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			return filter, fmt.Errorf("invalid since: %w", err)
+		}
+		filter.Since = value
+	}
+	if after := query.Get("afterId"); after != "" {
+		value, err := strconv.ParseInt(after, 10, 64)
+		if err != nil {
+			return filter, fmt.Errorf("invalid afterId: %w", err)
+		}
+		filter.AfterID = value
+	}
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil {
+			return filter, fmt.Errorf("invalid limit: %w", err)
+		}
+		filter.Limit = value
+	}
+
+	return filter, filter.Compile()
+}
@@ -0,0 +1,119 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tkc/go-json-server/src/config"
+	"github.com/tkc/go-json-server/src/internal/httputil"
+)
+
+// errNoResponseFile is returned for endpoints without a response file
+var errNoResponseFile = errors.New("endpoint has no response file")
+
+// responseFile is the representation of an endpoint's response file
+type responseFile struct {
+	Variant string `json:"variant,omitempty"`
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// getResponseFile returns the content of an endpoint's response file.
+// The variant query parameter selects a variant's file instead of the default one.
+func (a *Admin) getResponseFile(w http.ResponseWriter, r *http.Request) {
+	path, err := a.responsePath(r.PathValue("id"), r.URL.Query().Get("variant"))
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, responseFile{
+		Variant: r.URL.Query().Get("variant"),
+		Path:    path,
+		Content: string(content),
+	})
+}
+
+// updateResponseFile replaces the content of an endpoint's response file
+func (a *Admin) updateResponseFile(w http.ResponseWriter, r *http.Request) {
+	var body responseFile
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	id := r.PathValue("id")
+	path, err := a.responsePath(id, body.Variant)
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+
+	// Refuse to break JSON fixtures
+	if strings.EqualFold(filepath.Ext(path), ".json") && !json.Valid([]byte(body.Content)) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("content of %s is not valid JSON", path))
+		return
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err := os.WriteFile(path, []byte(body.Content), info.Mode().Perm()); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	a.Logger.InfoContext(r.Context(), "Response file updated", map[string]any{
+		"id":   id,
+		"file": path,
+	})
+	if a.OnChange != nil {
+		a.OnChange()
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, responseFile{
+		Variant: body.Variant,
+		Path:    path,
+		Content: body.Content,
+	})
+}
+
+// responsePath resolves the response file of an endpoint or one of its variants
+func (a *Admin) responsePath(id, variant string) (string, error) {
+	ep, ok := a.Config.GetEndpoint(id)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", config.ErrEndpointNotFound, id)
+	}
+
+	path := ep.JsonPath
+	if variant != "" {
+		v, ok := ep.Variants[variant]
+		if !ok {
+			return "", fmt.Errorf("%w: %s", config.ErrVariantNotFound, variant)
+		}
+		if v.JsonPath != "" {
+			path = v.JsonPath
+		}
+	}
+
+	if path == "" {
+		return "", errNoResponseFile
+	}
+	if err := a.checkFile(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
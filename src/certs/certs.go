@@ -0,0 +1,268 @@
+package certs
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+)
+
+// File names inside the cache directory
+const (
+	CAFile      = "ca.pem"
+	CAKeyFile   = "ca-key.pem"
+	CertFile    = "cert.pem"
+	CertKeyFile = "cert-key.pem"
+)
+
+const (
+	caValidity   = 10 * 365 * 24 * time.Hour
+	leafValidity = 825 * 24 * time.Hour
+
+	// renewBefore regenerates leaf certificates that are about to expire
+	renewBefore = 30 * 24 * time.Hour
+)
+
+// DefaultHosts are the names a generated certificate is valid for when none are configured
+var DefaultHosts = []string{"localhost", "127.0.0.1", "::1"}
+
+// ErrInvalidPEM is returned when a cached file does not hold the expected PEM block
+var ErrInvalidPEM = errors.New("invalid PEM data")
+
+// Bundle holds the paths of an auto-generated certificate and its CA
+type Bundle struct {
+	CAFile   string
+	CertFile string
+	KeyFile  string
+
+	// CACreated reports whether the CA was generated by this call
+	CACreated bool
+}
+
+// DefaultCacheDir returns the per-user directory where generated certificates are kept
+func DefaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "go-json-server", "tls")
+}
+
+// EnsureAuto returns a leaf certificate for hosts signed by a local CA, both
+// cached in dir. The CA is created once and reused so that it only has to be
+// trusted once; the leaf is regenerated when the hosts change or it is about to expire.
+func EnsureAuto(dir string, hosts []string) (*Bundle, error) {
+	if dir == "" {
+		dir = DefaultCacheDir()
+	}
+	if len(hosts) == 0 {
+		hosts = DefaultHosts
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create certificate directory: %w", err)
+	}
+
+	bundle := &Bundle{
+		CAFile:   filepath.Join(dir, CAFile),
+		CertFile: filepath.Join(dir, CertFile),
+		KeyFile:  filepath.Join(dir, CertKeyFile),
+	}
+
+	caCert, caKey, err := loadPair(bundle.CAFile, filepath.Join(dir, CAKeyFile))
+	if err != nil {
+		caCert, caKey, err = createCA(bundle.CAFile, filepath.Join(dir, CAKeyFile))
+		if err != nil {
+			return nil, err
+		}
+		bundle.CACreated = true
+	}
+
+	leaf, _, err := loadPair(bundle.CertFile, bundle.KeyFile)
+	if err == nil && leafUsable(leaf, caCert, hosts) {
+		return bundle, nil
+	}
+
+	if err := createLeaf(bundle.CertFile, bundle.KeyFile, hosts, caCert, caKey); err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
+
+// leafUsable reports whether a cached leaf covers hosts, is signed by ca and is not about to expire
+func leafUsable(leaf, ca *x509.Certificate, hosts []string) bool {
+	if time.Now().Add(renewBefore).After(leaf.NotAfter) {
+		return false
+	}
+	if leaf.CheckSignatureFrom(ca) != nil {
+		return false
+	}
+
+	var names []string
+	names = append(names, leaf.DNSNames...)
+	for _, ip := range leaf.IPAddresses {
+		names = append(names, ip.String())
+	}
+	return slices.Equal(normalizeHosts(names), normalizeHosts(hosts))
+}
+
+// normalizeHosts sorts hosts and canonicalizes IP addresses
+func normalizeHosts(hosts []string) []string {
+	out := make([]string, 0, len(hosts))
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			h = ip.String()
+		}
+		out = append(out, h)
+	}
+	sort.Strings(out)
+	return slices.Compact(out)
+}
+
+// createCA generates a self-signed CA certificate
+func createCA(certPath, keyPath string) (*x509.Certificate, crypto.Signer, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate CA key: %w", err)
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber(),
+		Subject:               pkix.Name{CommonName: "go-json-server local CA", Organization: []string{"go-json-server"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(caValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create CA certificate: %w", err)
+	}
+	if err := writePair(certPath, keyPath, der, key); err != nil {
+		return nil, nil, err
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	return cert, key, err
+}
+
+// createLeaf generates a server certificate for hosts signed by the CA
+func createLeaf(certPath, keyPath string, hosts []string, ca *x509.Certificate, caKey crypto.Signer) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("failed to generate certificate key: %w", err)
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serialNumber(),
+		Subject:      pkix.Name{CommonName: hosts[0], Organization: []string{"go-json-server"}},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(leafValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, h)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, key.Public(), caKey)
+	if err != nil {
+		return fmt.Errorf("failed to create certificate: %w", err)
+	}
+	return writePair(certPath, keyPath, der, key)
+}
+
+// loadPair reads a cached certificate and its private key
+func loadPair(certPath, keyPath string) (*x509.Certificate, crypto.Signer, error) {
+	certPEM, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyPEM, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	certBlock, _ := pem.Decode(certPEM)
+	if certBlock == nil || certBlock.Type != "CERTIFICATE" {
+		return nil, nil, fmt.Errorf("%w: %s", ErrInvalidPEM, certPath)
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrInvalidPEM, keyPath)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s is not a signing key", ErrInvalidPEM, keyPath)
+	}
+	return cert, signer, nil
+}
+
+// writePair stores a certificate and its private key as PEM files
+func writePair(certPath, keyPath string, der []byte, key crypto.Signer) error {
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("failed to encode private key: %w", err)
+	}
+
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		return fmt.Errorf("failed to write private key: %w", err)
+	}
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		return fmt.Errorf("failed to write certificate: %w", err)
+	}
+	return nil
+}
+
+// serialNumber returns a random 128-bit certificate serial number
+func serialNumber() *big.Int {
+	limit := new(big.Int).Lsh(big.NewInt(1), 128)
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		panic(fmt.Sprintf("crypto/rand failed: %v", err))
+	}
+	return n
+}
+
+// LoadCertPool reads a PEM bundle of CA certificates
+func LoadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("%w: no certificates in %s", ErrInvalidPEM, path)
+	}
+	return pool, nil
+}
@@ -0,0 +1,90 @@
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnsureAuto(t *testing.T) {
+	dir := t.TempDir()
+
+	bundle, err := EnsureAuto(dir, nil)
+	assert.NoError(t, err)
+	assert.True(t, bundle.CACreated)
+	assert.Equal(t, filepath.Join(dir, CAFile), bundle.CAFile)
+
+	// The leaf is a usable key pair that verifies against the CA for the default hosts
+	pair, err := tls.LoadX509KeyPair(bundle.CertFile, bundle.KeyFile)
+	assert.NoError(t, err)
+	leaf, err := x509.ParseCertificate(pair.Certificate[0])
+	assert.NoError(t, err)
+
+	caPEM, err := os.ReadFile(bundle.CAFile)
+	assert.NoError(t, err)
+	roots := x509.NewCertPool()
+	assert.True(t, roots.AppendCertsFromPEM(caPEM))
+	for _, host := range DefaultHosts {
+		_, err := leaf.Verify(x509.VerifyOptions{DNSName: host, Roots: roots})
+		assert.NoError(t, err, host)
+	}
+
+	// Private keys are not world readable
+	info, err := os.Stat(bundle.KeyFile)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// A second call reuses both the CA and the leaf
+	certPEM, _ := os.ReadFile(bundle.CertFile)
+	again, err := EnsureAuto(dir, []string{"::1", "localhost", "127.0.0.1"})
+	assert.NoError(t, err)
+	assert.False(t, again.CACreated)
+	certAgain, _ := os.ReadFile(again.CertFile)
+	assert.Equal(t, certPEM, certAgain)
+
+	// Changing the hosts issues a new leaf from the same CA
+	other, err := EnsureAuto(dir, []string{"api.local"})
+	assert.NoError(t, err)
+	assert.False(t, other.CACreated)
+	caAgain, _ := os.ReadFile(other.CAFile)
+	assert.Equal(t, caPEM, caAgain)
+
+	pair, err = tls.LoadX509KeyPair(other.CertFile, other.KeyFile)
+	assert.NoError(t, err)
+	leaf, _ = x509.ParseCertificate(pair.Certificate[0])
+	_, err = leaf.Verify(x509.VerifyOptions{DNSName: "api.local", Roots: roots})
+	assert.NoError(t, err)
+	_, err = leaf.Verify(x509.VerifyOptions{DNSName: "localhost", Roots: roots})
+	assert.Error(t, err)
+}
+
+func TestEnsureAuto_RecreatesCorruptCA(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, CAFile), []byte("garbage"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, CAKeyFile), []byte("garbage"), 0600))
+
+	bundle, err := EnsureAuto(dir, []string{"localhost"})
+	assert.NoError(t, err)
+	assert.True(t, bundle.CACreated)
+}
+
+func TestLoadCertPool(t *testing.T) {
+	dir := t.TempDir()
+	bundle, err := EnsureAuto(dir, nil)
+	assert.NoError(t, err)
+
+	pool, err := LoadCertPool(bundle.CAFile)
+	assert.NoError(t, err)
+	assert.NotNil(t, pool)
+
+	// Files without certificates are rejected
+	_, err = LoadCertPool(bundle.KeyFile)
+	assert.ErrorIs(t, err, ErrInvalidPEM)
+
+	_, err = LoadCertPool(filepath.Join(dir, "missing.pem"))
+	assert.Error(t, err)
+}
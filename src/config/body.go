@@ -0,0 +1,65 @@
+package config
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"path/filepath"
+)
+
+// Response body types of an endpoint's type setting, with their content types
+var BodyTypes = map[string]string{
+	"json":   "application/json; charset=UTF-8",
+	"text":   "text/plain; charset=UTF-8",
+	"html":   "text/html; charset=UTF-8",
+	"xml":    "application/xml; charset=UTF-8",
+	"csv":    "text/csv; charset=UTF-8",
+	"binary": "application/octet-stream",
+}
+
+// MediaType returns the content type set for the endpoint's response, by
+// contentType or by type, or "" when it is derived from the body
+func (ep Endpoint) MediaType() string {
+	if ep.ContentType != "" {
+		return ep.ContentType
+	}
+	return BodyTypes[ep.Type]
+}
+
+// InlineBody returns the body set in the config rather than in a response file
+func (ep Endpoint) InlineBody() ([]byte, error) {
+	if ep.BodyBase64 != "" {
+		return base64.StdEncoding.DecodeString(ep.BodyBase64)
+	}
+	return []byte(ep.Body), nil
+}
+
+// validateBody checks the body source, type and download file name of an endpoint
+func (ep Endpoint) validateBody() error {
+	sources := 0
+	for _, source := range []string{ep.JsonPath, ep.Body, ep.BodyBase64} {
+		if source != "" {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return errors.New("only one of jsonPath, body and bodyBase64 may be set")
+	}
+	if _, err := base64.StdEncoding.DecodeString(ep.BodyBase64); err != nil {
+		return fmt.Errorf("bad bodyBase64: %v", err)
+	}
+
+	if _, ok := BodyTypes[ep.Type]; ep.Type != "" && !ok {
+		return fmt.Errorf("unknown type %q", ep.Type)
+	}
+	if ep.ContentType != "" {
+		if _, _, err := mime.ParseMediaType(ep.ContentType); err != nil {
+			return fmt.Errorf("bad contentType %q: %v", ep.ContentType, err)
+		}
+	}
+	if ep.Download != "" && filepath.Base(ep.Download) != ep.Download {
+		return fmt.Errorf("bad download name %q: use a file name without directories", ep.Download)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Default bounds of the response cache
+const (
+	DefaultCacheMaxEntries = 1000
+	DefaultCacheMaxBytes   = 64 << 20
+)
+
+// CacheConfig represents the response cache settings. Responses are keyed on
+// the method, path and query; IgnoreQuery leaves the query out, KeyHeaders adds
+// the values of request headers and KeyBody adds a hash of the request body.
+// TTL is in seconds, zero meaning the server's default.
+//
+// An endpoint's cache settings take precedence over the global ones: its
+// Disabled, IgnoreQuery and KeyBody replace the global values, while an unset
+// TTL or KeyHeaders falls back to them. The bounds are server-wide.
+type CacheConfig struct {
+	Disabled    bool     `json:"disabled,omitempty"`
+	TTL         int      `json:"ttl,omitempty"`
+	MaxEntries  int      `json:"maxEntries,omitempty"`
+	MaxBytes    int64    `json:"maxBytes,omitempty"`
+	IgnoreQuery bool     `json:"ignoreQuery,omitempty"`
+	KeyHeaders  []string `json:"keyHeaders,omitempty"`
+	KeyBody     bool     `json:"keyBody,omitempty"`
+}
+
+// validate checks the cache settings
+func (cc *CacheConfig) validate() error {
+	if cc.TTL < 0 {
+		return errors.New("ttl must not be negative")
+	}
+	if cc.MaxEntries < 0 {
+		return errors.New("maxEntries must not be negative")
+	}
+	if cc.MaxBytes < 0 {
+		return errors.New("maxBytes must not be negative")
+	}
+	for _, name := range cc.KeyHeaders {
+		if !headerToken.MatchString(name) {
+			return fmt.Errorf("bad key header name %q", name)
+		}
+	}
+	return nil
+}
+
+// validateEndpoint checks an endpoint's cache settings, which cannot set the bounds
+func (cc *CacheConfig) validateEndpoint() error {
+	if cc.MaxEntries != 0 || cc.MaxBytes != 0 {
+		return errors.New("maxEntries and maxBytes can only be set globally")
+	}
+	return cc.validate()
+}
+
+// GetCache returns the global cache settings with the default bounds applied
+func (c *Config) GetCache() CacheConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	cache := c.Cache
+	if cache.MaxEntries == 0 {
+		cache.MaxEntries = DefaultCacheMaxEntries
+	}
+	if cache.MaxBytes == 0 {
+		cache.MaxBytes = DefaultCacheMaxBytes
+	}
+	return cache
+}
+
+// ResolveCache returns the cache settings in effect for an endpoint
+func (c *Config) ResolveCache(ep *Endpoint) CacheConfig {
+	cache := c.GetCache()
+	if ep == nil || ep.Cache == nil {
+		return cache
+	}
+
+	cache.Disabled = ep.Cache.Disabled
+	cache.IgnoreQuery = ep.Cache.IgnoreQuery
+	cache.KeyBody = ep.Cache.KeyBody
+	if ep.Cache.TTL > 0 {
+		cache.TTL = ep.Cache.TTL
+	}
+	if len(ep.Cache.KeyHeaders) > 0 {
+		cache.KeyHeaders = ep.Cache.KeyHeaders
+	}
+	return cache
+}
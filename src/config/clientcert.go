@@ -0,0 +1,88 @@
+package config
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Client certificate modes of the TLS listener
+const (
+	ClientAuthRequest = "request"
+	ClientAuthRequire = "require"
+)
+
+// ClientCertMatch selects requests by their verified client certificate.
+// Fields are glob patterns in path.Match syntax and every non-empty field must match.
+type ClientCertMatch struct {
+	CommonName   string `json:"commonName,omitempty"`
+	Organization string `json:"organization,omitempty"`
+	SAN          string `json:"san,omitempty"`
+}
+
+// String renders the match in a stable form for identifiers and messages
+func (m ClientCertMatch) String() string {
+	return fmt.Sprintf("cn=%s,o=%s,san=%s", m.CommonName, m.Organization, m.SAN)
+}
+
+// validate checks that the match has at least one well-formed pattern
+func (m ClientCertMatch) validate() error {
+	if m.CommonName == "" && m.Organization == "" && m.SAN == "" {
+		return errors.New("clientCert needs at least one of commonName, organization or san")
+	}
+	for _, pattern := range []string{m.CommonName, m.Organization, m.SAN} {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("bad clientCert pattern %q: %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Matches reports whether the certificate satisfies the match
+func (m ClientCertMatch) Matches(cert *x509.Certificate) bool {
+	if cert == nil {
+		return false
+	}
+	if m.CommonName != "" && !globMatch(m.CommonName, cert.Subject.CommonName) {
+		return false
+	}
+	if m.Organization != "" && !anyGlobMatch(m.Organization, cert.Subject.Organization) {
+		return false
+	}
+	if m.SAN != "" && !anyGlobMatch(m.SAN, CertSANs(cert)) {
+		return false
+	}
+	return true
+}
+
+// CertSANs returns all subject alternative names of a certificate
+func CertSANs(cert *x509.Certificate) []string {
+	sans := make([]string, 0, len(cert.DNSNames)+len(cert.EmailAddresses)+len(cert.IPAddresses)+len(cert.URIs))
+	sans = append(sans, cert.DNSNames...)
+	sans = append(sans, cert.EmailAddresses...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+	return sans
+}
+
+// globMatch matches value against a path.Match pattern, case-insensitively
+func globMatch(pattern, value string) bool {
+	ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(value))
+	return ok
+}
+
+// anyGlobMatch reports whether any value matches the pattern
+func anyGlobMatch(pattern string, values []string) bool {
+	for _, v := range values {
+		if globMatch(pattern, v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientCertMatch_Matches(t *testing.T) {
+	cert := &x509.Certificate{
+		Subject:        pkix.Name{CommonName: "partner-a", Organization: []string{"Acme", "Partners"}},
+		DNSNames:       []string{"partner-a.example.com"},
+		EmailAddresses: []string{"ops@example.com"},
+		IPAddresses:    []net.IP{net.ParseIP("10.0.0.1")},
+	}
+
+	tests := []struct {
+		name  string
+		match ClientCertMatch
+		want  bool
+	}{
+		{"common name", ClientCertMatch{CommonName: "partner-a"}, true},
+		{"common name glob", ClientCertMatch{CommonName: "partner-*"}, true},
+		{"common name is case insensitive", ClientCertMatch{CommonName: "PARTNER-A"}, true},
+		{"other common name", ClientCertMatch{CommonName: "partner-b"}, false},
+		{"any organization", ClientCertMatch{Organization: "Partners"}, true},
+		{"DNS SAN", ClientCertMatch{SAN: "*.example.com"}, true},
+		{"email SAN", ClientCertMatch{SAN: "ops@example.com"}, true},
+		{"IP SAN", ClientCertMatch{SAN: "10.0.0.1"}, true},
+		{"all fields must match", ClientCertMatch{CommonName: "partner-a", SAN: "partner-b.*"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.match.Matches(cert))
+		})
+	}
+
+	// Requests without a certificate never match
+	assert.False(t, ClientCertMatch{CommonName: "*"}.Matches(nil))
+}
+
+func TestClientCertMatch_validate(t *testing.T) {
+	assert.Error(t, ClientCertMatch{}.validate())
+	assert.Error(t, ClientCertMatch{CommonName: "[a-"}.validate())
+	assert.NoError(t, ClientCertMatch{SAN: "*.example.com"}.validate())
+}
+
+func TestEndpointID_ClientCert(t *testing.T) {
+	plain := Endpoint{Method: "GET", Path: "/orders"}
+	partnerA := Endpoint{Method: "GET", Path: "/orders", ClientCert: &ClientCertMatch{CommonName: "partner-a"}}
+	partnerB := Endpoint{Method: "GET", Path: "/orders", ClientCert: &ClientCertMatch{CommonName: "partner-b"}}
+
+	assert.NotEqual(t, EndpointID(plain), EndpointID(partnerA))
+	assert.NotEqual(t, EndpointID(partnerA), EndpointID(partnerB))
+}
@@ -4,12 +4,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
-	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
-	"time"
 
-	"github.com/fsnotify/fsnotify"
+	"github.com/tkc/go-json-server/src/listen"
 )
 
 // Error definitions
@@ -19,31 +21,320 @@ var (
 	ErrDuplicateEndpoint = errors.New("duplicate endpoint found")
 	ErrJSONFileNotFound  = errors.New("JSON file not found for endpoint")
 	ErrFolderNotFound    = errors.New("folder not found for endpoint")
+	ErrInvalidOIDC       = errors.New("invalid OIDC configuration")
+	ErrInvalidAuth       = errors.New("invalid auth configuration")
+	ErrInvalidRateLimit  = errors.New("invalid rate limit configuration")
+	ErrVariantNotFound   = errors.New("response variant not found")
+	ErrInvalidMetrics    = errors.New("invalid metrics configuration")
+	ErrInvalidTracing    = errors.New("invalid tracing configuration")
+	ErrInvalidTLS        = errors.New("invalid TLS configuration")
+	ErrInvalidListener   = errors.New("invalid listener configuration")
+	ErrInvalidHost       = errors.New("invalid virtual host configuration")
+	ErrInvalidResponse   = errors.New("invalid response file")
+	ErrInvalidPath       = errors.New("invalid path pattern")
+	ErrInvalidCORS       = errors.New("invalid CORS configuration")
+	ErrInvalidBody       = errors.New("invalid response body")
+	ErrInvalidHeaders    = errors.New("invalid response headers")
+	ErrInvalidLocale     = errors.New("invalid locale configuration")
+	ErrInvalidCache      = errors.New("invalid cache configuration")
+	ErrPersist           = errors.New("failed to persist endpoints")
 )
 
-// Endpoint represents a single API endpoint configuration
+// Endpoint represents a single API endpoint configuration. The response body
+// comes from the jsonPath file, which may hold any content, or inline from
+// body or bodyBase64. Locales maps language tags to localized response files,
+// and DefaultLocale overrides the server's default locale for the endpoint.
 type Endpoint struct {
-	Type     string `json:"type"`
-	Method   string `json:"method"`
+	ID            string                     `json:"id,omitempty"`
+	Type          string                     `json:"type,omitempty"`
+	Method        string                     `json:"method,omitempty"`
+	Status        int                        `json:"status,omitempty"`
+	Path          string                     `json:"path"`
+	JsonPath      string                     `json:"jsonPath,omitempty"`
+	Body          string                     `json:"body,omitempty"`
+	BodyBase64    string                     `json:"bodyBase64,omitempty"`
+	ContentType   string                     `json:"contentType,omitempty"`
+	Download      string                     `json:"download,omitempty"`
+	Headers       map[string]string          `json:"headers,omitempty"`
+	Cookies       map[string]CookieConfig    `json:"cookies,omitempty"`
+	Locales       map[string]string          `json:"locales,omitempty"`
+	DefaultLocale string                     `json:"defaultLocale,omitempty"`
+	Folder        string                     `json:"folder,omitempty"`
+	Disabled      bool                       `json:"disabled,omitempty"`
+	Variants      map[string]ResponseVariant `json:"variants,omitempty"`
+	Variant       string                     `json:"variant,omitempty"`
+	Auth          *AuthConfig                `json:"auth,omitempty"`
+	RateLimit     *RateLimitConfig           `json:"rateLimit,omitempty"`
+	CORS          *CORSConfig                `json:"cors,omitempty"`
+	Cache         *CacheConfig               `json:"cache,omitempty"`
+	ClientCert    *ClientCertMatch           `json:"clientCert,omitempty"`
+	Hosts         []string                   `json:"hosts,omitempty"`
+}
+
+// ResponseVariant represents an alternative response an endpoint can be switched to
+type ResponseVariant struct {
 	Status   int    `json:"status"`
-	Path     string `json:"path"`
 	JsonPath string `json:"jsonPath"`
-	Folder   string `json:"folder"`
+}
+
+// ActiveResponse returns the status and response file of the selected variant,
+// falling back to the endpoint's own response
+func (ep Endpoint) ActiveResponse() (status int, jsonPath string) {
+	status, jsonPath = ep.Status, ep.JsonPath
+	if variant, ok := ep.Variants[ep.Variant]; ok {
+		if variant.Status != 0 {
+			status = variant.Status
+		}
+		if variant.JsonPath != "" {
+			jsonPath = variant.JsonPath
+		}
+	}
+	return status, jsonPath
+}
+
+// BasicAuthUser represents a credential pair accepted by basic auth
+type BasicAuthUser struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// AuthConfig represents the protection applied to an endpoint.
+// A request is accepted when any of the configured schemes accepts it.
+type AuthConfig struct {
+	Disabled      bool            `json:"disabled"`
+	Realm         string          `json:"realm"`
+	Basic         []BasicAuthUser `json:"basic"`
+	APIKeys       []string        `json:"apiKeys"`
+	APIKeyHeader  string          `json:"apiKeyHeader"`
+	APIKeyQuery   string          `json:"apiKeyQuery"`
+	Bearer        bool            `json:"bearer"`
+	FailureStatus int             `json:"failureStatus"`
+	FailureBody   string          `json:"failureBody"`
+}
+
+// OIDCClient represents an OAuth2 client registered with the mock provider
+type OIDCClient struct {
+	ClientID     string   `json:"clientId"`
+	ClientSecret string   `json:"clientSecret"`
+	RedirectURIs []string `json:"redirectUris"`
+}
+
+// OIDCUser represents a user that can sign in to the mock provider
+type OIDCUser struct {
+	Username string         `json:"username"`
+	Password string         `json:"password"`
+	Subject  string         `json:"subject"`
+	Claims   map[string]any `json:"claims"`
+}
+
+// OIDCConfig represents the built-in mock OpenID Connect provider configuration
+type OIDCConfig struct {
+	Enabled         bool         `json:"enabled"`
+	Issuer          string       `json:"issuer"`
+	PathPrefix      string       `json:"pathPrefix"`
+	AccessTokenTTL  int          `json:"accessTokenTTL"`
+	RefreshTokenTTL int          `json:"refreshTokenTTL"`
+	Clients         []OIDCClient `json:"clients"`
+	Users           []OIDCUser   `json:"users"`
+	// AutoLogin signs in the user named by login_hint without a password
+	AutoLogin bool `json:"autoLogin"`
+}
+
+// AdminConfig represents the runtime admin API settings
+type AdminConfig struct {
+	Enabled bool `json:"enabled"`
+	Port    int  `json:"port"`
+	Persist bool `json:"persist"`
+}
+
+// MetricsConfig represents the Prometheus metrics settings
+type MetricsConfig struct {
+	Enabled bool   `json:"enabled"`
+	Path    string `json:"path"`
+}
+
+// TracingConfig represents the distributed tracing settings
+type TracingConfig struct {
+	Enabled      bool              `json:"enabled"`
+	ServiceName  string            `json:"serviceName,omitempty"`
+	File         string            `json:"file,omitempty"`
+	CollectorURL string            `json:"collectorURL,omitempty"`
+	Headers      map[string]string `json:"headers,omitempty"`
+}
+
+// validate checks the exporter settings
+func (t *TracingConfig) validate() error {
+	if t.CollectorURL == "" {
+		return nil
+	}
+	u, err := url.Parse(t.CollectorURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("collectorURL must be an http(s) URL, got %q", t.CollectorURL)
+	}
+	return nil
+}
+
+// TLSConfig represents the HTTPS settings
+type TLSConfig struct {
+	CertFile string   `json:"certFile,omitempty"`
+	KeyFile  string   `json:"keyFile,omitempty"`
+	Auto     bool     `json:"auto,omitempty"`
+	Hosts    []string `json:"hosts,omitempty"`
+	CacheDir string   `json:"cacheDir,omitempty"`
+	HTTPPort int      `json:"httpPort,omitempty"`
+
+	// ClientAuth requests ("request") or requires ("require") client
+	// certificates verified against ClientCAFile
+	ClientAuth   string `json:"clientAuth,omitempty"`
+	ClientCAFile string `json:"clientCAFile,omitempty"`
+}
+
+// Enabled reports whether the server is served over HTTPS
+func (t TLSConfig) Enabled() bool {
+	return t.Auto || t.CertFile != ""
+}
+
+// validate checks that a certificate source is configured consistently
+func (t *TLSConfig) validate() error {
+	if t.Auto && (t.CertFile != "" || t.KeyFile != "") {
+		return errors.New("auto cannot be combined with certFile and keyFile")
+	}
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return errors.New("certFile and keyFile must be set together")
+	}
+	for _, path := range []string{t.CertFile, t.KeyFile} {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("cannot read %s: %v", path, err)
+		}
+	}
+	if t.HTTPPort != 0 && !t.Enabled() {
+		return errors.New("httpPort requires TLS to be enabled")
+	}
+
+	switch t.ClientAuth {
+	case "":
+		return nil
+	case ClientAuthRequest, ClientAuthRequire:
+	default:
+		return fmt.Errorf("unknown clientAuth %q", t.ClientAuth)
+	}
+	if !t.Enabled() {
+		return errors.New("clientAuth requires TLS to be enabled")
+	}
+	if t.ClientCAFile == "" {
+		return errors.New("clientAuth requires clientCAFile")
+	}
+	if _, err := os.Stat(t.ClientCAFile); err != nil {
+		return fmt.Errorf("cannot read %s: %v", t.ClientCAFile, err)
+	}
+	return nil
+}
+
+// ListenerConfig represents an additional address the server listens on
+type ListenerConfig struct {
+	// Address is host:port or unix:/path/to.sock
+	Address string `json:"address"`
+	// Mode sets the permissions of a Unix socket, in octal (e.g. "0660")
+	Mode string `json:"mode,omitempty"`
+}
+
+// FileMode returns the parsed socket permissions, zero when unset
+func (l ListenerConfig) FileMode() (os.FileMode, error) {
+	if l.Mode == "" {
+		return 0, nil
+	}
+	mode, err := strconv.ParseUint(l.Mode, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, fmt.Errorf("mode must be octal permissions like 0660, got %q", l.Mode)
+	}
+	return os.FileMode(mode), nil
+}
+
+// validate checks the listener address and socket mode
+func (l ListenerConfig) validate() error {
+	network, _, err := listen.ParseAddress(l.Address)
+	if err != nil {
+		return err
+	}
+	if l.Mode != "" && network != "unix" {
+		return fmt.Errorf("mode only applies to unix sockets, got %s", l.Address)
+	}
+	_, err = l.FileMode()
+	return err
+}
+
+// JournalConfig represents the request journal settings
+type JournalConfig struct {
+	Capacity int `json:"capacity"`
 }
 
 // Config represents the main configuration structure
 type Config struct {
-	Host      string     `json:"host"`
-	Port      int        `json:"port"`
-	LogLevel  string     `json:"logLevel"`
-	LogFormat string     `json:"logFormat"`
-	LogPath   string     `json:"logPath"`
-	Endpoints []Endpoint `json:"endpoints"`
-	mu        sync.RWMutex
+	Host         string             `json:"host"`
+	Port         int                `json:"port"`
+	LogLevel     string             `json:"logLevel"`
+	LogFormat    string             `json:"logFormat"`
+	LogPath      string             `json:"logPath"`
+	OIDC         OIDCConfig         `json:"oidc"`
+	Auth         *AuthConfig        `json:"auth,omitempty"`
+	RateLimit    *RateLimitConfig   `json:"rateLimit,omitempty"`
+	CORS         *CORSConfig        `json:"cors,omitempty"`
+	Localization LocalizationConfig `json:"localization"`
+	Cache        CacheConfig        `json:"cache"`
+	Admin        AdminConfig        `json:"admin"`
+	Journal      JournalConfig      `json:"journal"`
+	Metrics      MetricsConfig      `json:"metrics"`
+	Tracing      TracingConfig      `json:"tracing"`
+	TLS          TLSConfig          `json:"tls"`
+	Listeners    []ListenerConfig   `json:"listeners,omitempty"`
+	VirtualHosts []VirtualHost      `json:"virtualHosts,omitempty"`
+	Endpoints    []Endpoint         `json:"endpoints"`
+
+	// Overrides is applied to every reloaded configuration before it is
+	// validated, so that settings such as command line flags survive a reload
+	Overrides func(*Config) `json:"-"`
+
+	mu sync.RWMutex
+	// generation counts the changes to the endpoints
+	generation uint64
+	// virtualHostGroups are the hosts of the virtual hosts in the config
+	// file, so that saved endpoints keep their grouping
+	virtualHostGroups [][]string
+	// persistPath is the file endpoint changes are written to
+	persistPath string
 }
 
 // LoadConfig loads configuration from a file path
 func LoadConfig(path string) (*Config, error) {
+	return LoadConfigWithOverrides(path, nil)
+}
+
+// LoadConfigWithOverrides loads configuration from a file path, applying
+// overrides before it is validated. The overrides are kept for reloads.
+func LoadConfigWithOverrides(path string, overrides func(*Config)) (*Config, error) {
+	config, err := loadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	if overrides != nil {
+		overrides(config)
+		config.Overrides = overrides
+	}
+
+	// Validate configuration
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// loadConfig reads a configuration file and applies the defaults without
+// validating it
+func loadConfig(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("error reading config file: %w", err)
@@ -64,10 +355,29 @@ func LoadConfig(path string) (*Config, error) {
 	if config.LogFormat == "" {
 		config.LogFormat = "text"
 	}
+	if config.OIDC.PathPrefix == "" {
+		config.OIDC.PathPrefix = "/oidc"
+	}
+	if config.OIDC.AccessTokenTTL == 0 {
+		config.OIDC.AccessTokenTTL = 3600
+	}
+	if config.OIDC.RefreshTokenTTL == 0 {
+		config.OIDC.RefreshTokenTTL = 86400
+	}
+	if config.Metrics.Path == "" {
+		config.Metrics.Path = "/metrics"
+	}
 
-	// Validate configuration
-	if err := config.Validate(); err != nil {
-		return nil, err
+	// Virtual host endpoints are kept in the endpoint list, tagged with their hosts
+	if err := config.flattenVirtualHosts(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidHost, err)
+	}
+
+	// Give every endpoint a stable identifier for the admin API
+	for i := range config.Endpoints {
+		if config.Endpoints[i].ID == "" {
+			config.Endpoints[i].ID = EndpointID(config.Endpoints[i])
+		}
 	}
 
 	return &config, nil
@@ -79,13 +389,115 @@ func (c *Config) Validate() error {
 		return ErrNoEndpoints
 	}
 
+	if c.RateLimit != nil {
+		if err := c.RateLimit.validate(); err != nil {
+			return fmt.Errorf("%w: global rate limit: %v", ErrInvalidRateLimit, err)
+		}
+	}
+
+	if c.CORS != nil {
+		if err := c.CORS.validate(); err != nil {
+			return fmt.Errorf("%w: global cors: %v", ErrInvalidCORS, err)
+		}
+	}
+
+	if err := c.Cache.validate(); err != nil {
+		return fmt.Errorf("%w: global cache: %v", ErrInvalidCache, err)
+	}
+
+	if c.Metrics.Enabled && !strings.HasPrefix(c.Metrics.Path, "/") {
+		return fmt.Errorf("%w: path must start with /", ErrInvalidMetrics)
+	}
+
+	if err := c.Tracing.validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTracing, err)
+	}
+
+	if err := c.TLS.validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTLS, err)
+	}
+
+	addresses := make(map[string]bool)
+	for _, l := range c.Listeners {
+		if err := l.validate(); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidListener, err)
+		}
+		if addresses[l.Address] {
+			return fmt.Errorf("%w: duplicate address %s", ErrInvalidListener, l.Address)
+		}
+		addresses[l.Address] = true
+	}
+
+	usesBearer := false
+	if c.Auth != nil {
+		if err := c.Auth.validate(); err != nil {
+			return fmt.Errorf("%w: global auth: %v", ErrInvalidAuth, err)
+		}
+		usesBearer = c.Auth.Bearer
+	}
+
 	// Check for duplicate paths and methods
 	pathMethods := make(map[string]bool)
+	ids := make(map[string]bool)
 	for _, ep := range c.Endpoints {
 		if ep.Path == "" {
 			return fmt.Errorf("%w: empty path in endpoint", ErrEmptyPath)
 		}
 
+		if ep.ID != "" {
+			if ids[ep.ID] {
+				return fmt.Errorf("%w: duplicate id %s", ErrDuplicateEndpoint, ep.ID)
+			}
+			ids[ep.ID] = true
+		}
+
+		if ep.Auth != nil {
+			if err := ep.Auth.validate(); err != nil {
+				return fmt.Errorf("%w: %s %s: %v", ErrInvalidAuth, ep.Method, ep.Path, err)
+			}
+			usesBearer = usesBearer || ep.Auth.Bearer
+		}
+
+		if ep.RateLimit != nil {
+			if err := ep.RateLimit.validate(); err != nil {
+				return fmt.Errorf("%w: %s %s: %v", ErrInvalidRateLimit, ep.Method, ep.Path, err)
+			}
+		}
+
+		if err := ep.validateBody(); err != nil {
+			return fmt.Errorf("%w: %s %s: %v", ErrInvalidBody, ep.Method, ep.Path, err)
+		}
+
+		if err := ep.validateLocales(c.Localization.DefaultLocale); err != nil {
+			return fmt.Errorf("%w: %s %s: %v", ErrInvalidLocale, ep.Method, ep.Path, err)
+		}
+
+		if err := ep.validateHeaders(); err != nil {
+			return fmt.Errorf("%w: %s %s: %v", ErrInvalidHeaders, ep.Method, ep.Path, err)
+		}
+
+		if ep.CORS != nil {
+			if err := ep.CORS.validate(); err != nil {
+				return fmt.Errorf("%w: %s %s: %v", ErrInvalidCORS, ep.Method, ep.Path, err)
+			}
+		}
+
+		if ep.Cache != nil {
+			if err := ep.Cache.validateEndpoint(); err != nil {
+				return fmt.Errorf("%w: %s %s: %v", ErrInvalidCache, ep.Method, ep.Path, err)
+			}
+		}
+
+		if err := validateHosts(ep.Hosts); err != nil {
+			return fmt.Errorf("%w: %s %s: %v", ErrInvalidHost, ep.Method, ep.Path, err)
+		}
+
+		if ep.ClientCert != nil {
+			if err := ep.ClientCert.validate(); err != nil {
+				return fmt.Errorf("%w: %s %s: %v", ErrInvalidTLS, ep.Method, ep.Path, err)
+			}
+		}
+
 		// Skip method duplication check for file servers
 		if ep.Folder != "" {
 			// Check folder existence
@@ -95,7 +507,16 @@ func (c *Config) Validate() error {
 			continue
 		}
 
-		pathMethod := ep.Path + ":" + ep.Method
+		if _, err := ParsePath(ep.Path); err != nil {
+			return fmt.Errorf("%w: %s %s: %v", ErrInvalidPath, ep.Method, ep.Path, err)
+		}
+
+		// Endpoints may share a path and method when they serve different
+		// virtual hosts or match different client certificates
+		pathMethod := HostsKey(ep.Hosts) + ":" + ep.Path + ":" + ep.Method
+		if ep.ClientCert != nil {
+			pathMethod += ":" + ep.ClientCert.String()
+		}
 		if pathMethods[pathMethod] {
 			return fmt.Errorf("%w: %s %s", ErrDuplicateEndpoint, ep.Method, ep.Path)
 		}
@@ -107,17 +528,147 @@ func (c *Config) Validate() error {
 				return fmt.Errorf("%w: %s for %s %s", ErrJSONFileNotFound, ep.JsonPath, ep.Method, ep.Path)
 			}
 		}
+
+		// Check response variants
+		for name, variant := range ep.Variants {
+			if variant.JsonPath == "" {
+				continue
+			}
+			if _, err := os.Stat(variant.JsonPath); os.IsNotExist(err) {
+				return fmt.Errorf("%w: %s for variant %s of %s %s", ErrJSONFileNotFound, variant.JsonPath, name, ep.Method, ep.Path)
+			}
+		}
+		for tag, file := range ep.Locales {
+			if _, err := os.Stat(file); os.IsNotExist(err) {
+				return fmt.Errorf("%w: %s for locale %s of %s %s", ErrJSONFileNotFound, file, tag, ep.Method, ep.Path)
+			}
+		}
+		if _, ok := ep.Variants[ep.Variant]; ep.Variant != "" && !ok {
+			return fmt.Errorf("%w: %s for %s %s", ErrVariantNotFound, ep.Variant, ep.Method, ep.Path)
+		}
 	}
 
+	if c.OIDC.Enabled {
+		if err := c.OIDC.validate(); err != nil {
+			return err
+		}
+	} else if usesBearer {
+		return fmt.Errorf("%w: bearer auth requires the oidc provider to be enabled", ErrInvalidAuth)
+	}
+
+	return nil
+}
+
+// validate checks the mock OIDC provider settings
+func (o *OIDCConfig) validate() error {
+	if o.PathPrefix != "" && !strings.HasPrefix(o.PathPrefix, "/") {
+		return fmt.Errorf("%w: pathPrefix must start with /", ErrInvalidOIDC)
+	}
+
+	clientIDs := make(map[string]bool)
+	for _, client := range o.Clients {
+		if client.ClientID == "" {
+			return fmt.Errorf("%w: client with empty clientId", ErrInvalidOIDC)
+		}
+		if clientIDs[client.ClientID] {
+			return fmt.Errorf("%w: duplicate client %s", ErrInvalidOIDC, client.ClientID)
+		}
+		clientIDs[client.ClientID] = true
+	}
+
+	usernames := make(map[string]bool)
+	for _, user := range o.Users {
+		if user.Username == "" {
+			return fmt.Errorf("%w: user with empty username", ErrInvalidOIDC)
+		}
+		if usernames[user.Username] {
+			return fmt.Errorf("%w: duplicate user %s", ErrInvalidOIDC, user.Username)
+		}
+		usernames[user.Username] = true
+	}
+
+	return nil
+}
+
+// validate checks that the auth settings can be enforced
+func (a *AuthConfig) validate() error {
+	if a.Disabled {
+		return nil
+	}
+	if len(a.Basic) == 0 && len(a.APIKeys) == 0 && !a.Bearer {
+		return errors.New("no basic users, API keys or bearer auth configured")
+	}
+	for _, user := range a.Basic {
+		if user.Username == "" {
+			return errors.New("basic auth user with empty username")
+		}
+	}
+	if a.FailureStatus != 0 && (a.FailureStatus < 400 || a.FailureStatus > 599) {
+		return fmt.Errorf("failure status %d is not an error status", a.FailureStatus)
+	}
+	return nil
+}
+
+// Rate limit algorithms
+const (
+	RateLimitFixedWindow = "fixed-window"
+	RateLimitTokenBucket = "token-bucket"
+)
+
+// Rate limit key sources
+const (
+	RateLimitKeyIP     = "ip"
+	RateLimitKeyAPIKey = "apiKey"
+	RateLimitKeyHeader = "header"
+)
+
+// RateLimitConfig represents a simulated rate limit policy
+type RateLimitConfig struct {
+	Algorithm   string `json:"algorithm"`
+	Limit       int    `json:"limit"`
+	Window      int    `json:"window"`
+	KeyBy       string `json:"keyBy"`
+	KeyHeader   string `json:"keyHeader"`
+	FailureBody string `json:"failureBody"`
+}
+
+// validate checks that the rate limit policy is usable
+func (rl *RateLimitConfig) validate() error {
+	if rl.Limit <= 0 {
+		return errors.New("limit must be positive")
+	}
+	if rl.Window < 0 {
+		return errors.New("window must not be negative")
+	}
+	switch rl.Algorithm {
+	case "", RateLimitFixedWindow, RateLimitTokenBucket:
+	default:
+		return fmt.Errorf("unknown algorithm %q", rl.Algorithm)
+	}
+	switch rl.KeyBy {
+	case "", RateLimitKeyIP, RateLimitKeyAPIKey:
+	case RateLimitKeyHeader:
+		if rl.KeyHeader == "" {
+			return errors.New("keyHeader is required when keying by header")
+		}
+	default:
+		return fmt.Errorf("unknown keyBy %q", rl.KeyBy)
+	}
 	return nil
 }
 
 // Reload reloads the configuration from disk
 func (c *Config) Reload(path string) error {
-	newConfig, err := LoadConfig(path)
+	newConfig, err := loadConfig(path)
 	if err != nil {
 		return err
 	}
+	if c.Overrides != nil {
+		c.Overrides(newConfig)
+	}
+	if err := newConfig.Validate(); err != nil {
+		return err
+	}
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -127,11 +678,37 @@ func (c *Config) Reload(path string) error {
 	c.LogLevel = newConfig.LogLevel
 	c.LogFormat = newConfig.LogFormat
 	c.LogPath = newConfig.LogPath
+	c.OIDC = newConfig.OIDC
+	c.Auth = newConfig.Auth
+	c.RateLimit = newConfig.RateLimit
+	c.CORS = newConfig.CORS
+	c.Localization = newConfig.Localization
+	c.Cache = newConfig.Cache
+	c.Admin = newConfig.Admin
+	c.Journal = newConfig.Journal
+	c.Metrics = newConfig.Metrics
+	c.Tracing = newConfig.Tracing
+	c.TLS = newConfig.TLS
+	c.Listeners = newConfig.Listeners
 	c.Endpoints = newConfig.Endpoints
+	c.virtualHostGroups = newConfig.virtualHostGroups
+	c.generation++
 
 	return nil
 }
 
+// ListenAddresses returns the configured listeners, or a single listener on
+// host:port when none are configured
+func (c *Config) ListenAddresses() []ListenerConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if len(c.Listeners) == 0 {
+		return []ListenerConfig{{Address: listen.Address(c.Host, c.Port)}}
+	}
+	return slices.Clone(c.Listeners)
+}
+
 // GetEndpoints returns a thread-safe copy of endpoints
 func (c *Config) GetEndpoints() []Endpoint {
 	c.mu.RLock()
@@ -144,6 +721,23 @@ func (c *Config) GetEndpoints() []Endpoint {
 	return endpoints
 }
 
+// GetEndpointsWithGeneration returns a copy of the endpoints together with
+// the generation they belong to
+func (c *Config) GetEndpointsWithGeneration() ([]Endpoint, uint64) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return slices.Clone(c.Endpoints), c.generation
+}
+
+// Generation returns a counter that increases whenever the endpoints change
+// through a reload or the admin API
+func (c *Config) Generation() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.generation
+}
+
 // GetPort returns the port in a thread-safe manner
 func (c *Config) GetPort() int {
 	c.mu.RLock()
@@ -158,56 +752,56 @@ func (c *Config) GetHost() string {
 	return c.Host
 }
 
-// GetLogConfig returns logging configuration
-func (c *Config) GetLogConfig() (level, format, path string) {
+// GetOIDC returns the mock OIDC provider configuration in a thread-safe manner
+func (c *Config) GetOIDC() OIDCConfig {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return c.LogLevel, c.LogFormat, c.LogPath
+	return c.OIDC
 }
 
-// WatchConfig watches for changes in the config file and reloads when needed
-func WatchConfig(configPath string, config *Config, reloadCh chan<- bool) error {
-	watcher, err := fsnotify.NewWatcher()
-	if err != nil {
-		return fmt.Errorf("failed to create file watcher: %w", err)
-	}
-
-	go func() {
-		defer watcher.Close()
-
-		dir := filepath.Dir(configPath)
-		if err := watcher.Add(dir); err != nil {
-			fmt.Printf("Error watching directory %s: %v\n", dir, err)
-			return
-		}
-
-		for {
-			select {
-			case event, ok := <-watcher.Events:
-				if !ok {
-					return
-				}
-
-				if event.Name == configPath && (event.Op&fsnotify.Write == fsnotify.Write) {
-					// Add a small delay to wait for write completion
-					time.Sleep(100 * time.Millisecond)
-
-					fmt.Println("Config file changed, reloading...")
-					if err := config.Reload(configPath); err != nil {
-						fmt.Printf("Error reloading config: %v\n", err)
-					} else if reloadCh != nil {
-						reloadCh <- true
-					}
-				}
-
-			case err, ok := <-watcher.Errors:
-				if !ok {
-					return
-				}
-				fmt.Printf("Error watching config: %v\n", err)
-			}
-		}
-	}()
+// ResolveAuth returns the auth settings that apply to an endpoint, or nil if it is unprotected.
+// Endpoint settings take precedence over the global default.
+func (c *Config) ResolveAuth(ep Endpoint) *AuthConfig {
+	auth := ep.Auth
+	if auth == nil {
+		c.mu.RLock()
+		auth = c.Auth
+		c.mu.RUnlock()
+	}
 
-	return nil
+	if auth == nil || auth.Disabled {
+		return nil
+	}
+	return auth
+}
+
+// GetAdmin returns the admin API settings in a thread-safe manner
+func (c *Config) GetAdmin() AdminConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Admin
+}
+
+// GetTLS returns the HTTPS settings in a thread-safe manner
+func (c *Config) GetTLS() TLSConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	tlsConfig := c.TLS
+	tlsConfig.Hosts = slices.Clone(c.TLS.Hosts)
+	return tlsConfig
+}
+
+// GetRateLimit returns the global rate limit, or nil when there is none
+func (c *Config) GetRateLimit() *RateLimitConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.RateLimit
+}
+
+// GetLogConfig returns logging configuration
+func (c *Config) GetLogConfig() (level, format, path string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.LogLevel, c.LogFormat, c.LogPath
 }
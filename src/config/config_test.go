@@ -137,6 +137,231 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantError: true,
 		},
+		{
+			name: "Valid OIDC provider",
+			setupFn: func() Config {
+				return Config{
+					Endpoints: []Endpoint{
+						{Method: "GET", Path: "/test", JsonPath: jsonFile, Status: 200},
+					},
+					OIDC: OIDCConfig{
+						Enabled:    true,
+						PathPrefix: "/oidc",
+						Clients:    []OIDCClient{{ClientID: "spa"}},
+						Users:      []OIDCUser{{Username: "alice"}},
+					},
+				}
+			},
+			wantError: false,
+		},
+		{
+			name: "OIDC duplicate client",
+			setupFn: func() Config {
+				return Config{
+					Endpoints: []Endpoint{
+						{Method: "GET", Path: "/test", JsonPath: jsonFile, Status: 200},
+					},
+					OIDC: OIDCConfig{
+						Enabled: true,
+						Clients: []OIDCClient{{ClientID: "spa"}, {ClientID: "spa"}},
+					},
+				}
+			},
+			wantError: true,
+		},
+		{
+			name: "Auth without schemes",
+			setupFn: func() Config {
+				return Config{
+					Endpoints: []Endpoint{
+						{Method: "GET", Path: "/test", JsonPath: jsonFile, Status: 200, Auth: &AuthConfig{}},
+					},
+				}
+			},
+			wantError: true,
+		},
+		{
+			name: "Bearer auth without OIDC provider",
+			setupFn: func() Config {
+				return Config{
+					Auth: &AuthConfig{Bearer: true},
+					Endpoints: []Endpoint{
+						{Method: "GET", Path: "/test", JsonPath: jsonFile, Status: 200},
+					},
+				}
+			},
+			wantError: true,
+		},
+		{
+			name: "Rate limit without limit",
+			setupFn: func() Config {
+				return Config{
+					Endpoints: []Endpoint{
+						{Method: "GET", Path: "/test", JsonPath: jsonFile, Status: 200, RateLimit: &RateLimitConfig{Window: 60}},
+					},
+				}
+			},
+			wantError: true,
+		},
+		{
+			name: "Rate limit by header without header name",
+			setupFn: func() Config {
+				return Config{
+					RateLimit: &RateLimitConfig{Limit: 10, KeyBy: RateLimitKeyHeader},
+					Endpoints: []Endpoint{
+						{Method: "GET", Path: "/test", JsonPath: jsonFile, Status: 200},
+					},
+				}
+			},
+			wantError: true,
+		},
+		{
+			name: "Unknown active variant",
+			setupFn: func() Config {
+				return Config{
+					Endpoints: []Endpoint{
+						{Method: "GET", Path: "/test", JsonPath: jsonFile, Status: 200, Variant: "missing"},
+					},
+				}
+			},
+			wantError: true,
+		},
+		{
+			name: "Metrics path without leading slash",
+			setupFn: func() Config {
+				return Config{
+					Metrics: MetricsConfig{Enabled: true, Path: "metrics"},
+					Endpoints: []Endpoint{
+						{Method: "GET", Path: "/test", JsonPath: jsonFile, Status: 200},
+					},
+				}
+			},
+			wantError: true,
+		},
+		{
+			name: "Tracing collector URL without scheme",
+			setupFn: func() Config {
+				return Config{
+					Tracing: TracingConfig{Enabled: true, CollectorURL: "otel-collector:4318"},
+					Endpoints: []Endpoint{
+						{Method: "GET", Path: "/test", JsonPath: jsonFile, Status: 200},
+					},
+				}
+			},
+			wantError: true,
+		},
+		{
+			name: "TLS certificate without key",
+			setupFn: func() Config {
+				return Config{
+					TLS: TLSConfig{CertFile: jsonFile},
+					Endpoints: []Endpoint{
+						{Method: "GET", Path: "/test", JsonPath: jsonFile, Status: 200},
+					},
+				}
+			},
+			wantError: true,
+		},
+		{
+			name: "TLS auto combined with certificate files",
+			setupFn: func() Config {
+				return Config{
+					TLS: TLSConfig{Auto: true, CertFile: jsonFile, KeyFile: jsonFile},
+					Endpoints: []Endpoint{
+						{Method: "GET", Path: "/test", JsonPath: jsonFile, Status: 200},
+					},
+				}
+			},
+			wantError: true,
+		},
+		{
+			name: "HTTP port without TLS",
+			setupFn: func() Config {
+				return Config{
+					TLS: TLSConfig{HTTPPort: 8080},
+					Endpoints: []Endpoint{
+						{Method: "GET", Path: "/test", JsonPath: jsonFile, Status: 200},
+					},
+				}
+			},
+			wantError: true,
+		},
+		{
+			name: "Same path for different client certificates",
+			setupFn: func() Config {
+				return Config{
+					Endpoints: []Endpoint{
+						{Method: "GET", Path: "/test", JsonPath: jsonFile, Status: 200, ClientCert: &ClientCertMatch{CommonName: "a"}},
+						{Method: "GET", Path: "/test", JsonPath: jsonFile, Status: 200, ClientCert: &ClientCertMatch{CommonName: "b"}},
+						{Method: "GET", Path: "/test", JsonPath: jsonFile, Status: 200},
+					},
+				}
+			},
+			wantError: false,
+		},
+		{
+			name: "Client auth without CA bundle",
+			setupFn: func() Config {
+				return Config{
+					TLS: TLSConfig{Auto: true, ClientAuth: ClientAuthRequire},
+					Endpoints: []Endpoint{
+						{Method: "GET", Path: "/test", JsonPath: jsonFile, Status: 200},
+					},
+				}
+			},
+			wantError: true,
+		},
+		{
+			name: "Valid listeners",
+			setupFn: func() Config {
+				return Config{
+					Listeners: []ListenerConfig{
+						{Address: "127.0.0.1:3000"},
+						{Address: "unix:/run/mock.sock", Mode: "0660"},
+					},
+					Endpoints: []Endpoint{
+						{Method: "GET", Path: "/test", JsonPath: jsonFile, Status: 200},
+					},
+				}
+			},
+			wantError: false,
+		},
+		{
+			name: "Listener without port",
+			setupFn: func() Config {
+				return Config{
+					Listeners: []ListenerConfig{{Address: "127.0.0.1"}},
+					Endpoints: []Endpoint{
+						{Method: "GET", Path: "/test", JsonPath: jsonFile, Status: 200},
+					},
+				}
+			},
+			wantError: true,
+		},
+		{
+			name: "Socket mode on a TCP listener",
+			setupFn: func() Config {
+				return Config{
+					Listeners: []ListenerConfig{{Address: ":3000", Mode: "0660"}},
+					Endpoints: []Endpoint{
+						{Method: "GET", Path: "/test", JsonPath: jsonFile, Status: 200},
+					},
+				}
+			},
+			wantError: true,
+		},
+		{
+			name: "Invalid socket mode",
+			setupFn: func() Config {
+				return Config{
+					Listeners: []ListenerConfig{{Address: "unix:/run/mock.sock", Mode: "rw-rw----"}},
+					Endpoints: []Endpoint{
+						{Method: "GET", Path: "/test", JsonPath: jsonFile, Status: 200},
+					},
+				}
+			},
+			wantError: true,
+		},
 		{
 			name: "Folder not found",
 			setupFn: func() Config {
@@ -148,6 +373,163 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantError: true,
 		},
+		{
+			name: "Invalid path pattern",
+			setupFn: func() Config {
+				return Config{
+					Endpoints: []Endpoint{
+						{Method: "GET", Path: "/files/*rest/meta", JsonPath: jsonFile, Status: 200},
+					},
+				}
+			},
+			wantError: true,
+		},
+		{
+			name: "Inline body",
+			setupFn: func() Config {
+				return Config{
+					Endpoints: []Endpoint{
+						{Method: "GET", Path: "/status", Status: 200, Type: "text", Body: "OK", Download: "status.txt"},
+					},
+				}
+			},
+			wantError: false,
+		},
+		{
+			name: "Inline body together with response file",
+			setupFn: func() Config {
+				return Config{
+					Endpoints: []Endpoint{
+						{Method: "GET", Path: "/test", JsonPath: jsonFile, Body: "OK", Status: 200},
+					},
+				}
+			},
+			wantError: true,
+		},
+		{
+			name: "Invalid base64 body",
+			setupFn: func() Config {
+				return Config{
+					Endpoints: []Endpoint{
+						{Method: "GET", Path: "/test", BodyBase64: "not base64!", Status: 200},
+					},
+				}
+			},
+			wantError: true,
+		},
+		{
+			name: "Unknown body type",
+			setupFn: func() Config {
+				return Config{
+					Endpoints: []Endpoint{
+						{Method: "GET", Path: "/test", JsonPath: jsonFile, Type: "spreadsheet", Status: 200},
+					},
+				}
+			},
+			wantError: true,
+		},
+		{
+			name: "Invalid response header name",
+			setupFn: func() Config {
+				return Config{
+					Endpoints: []Endpoint{
+						{Method: "GET", Path: "/test", JsonPath: jsonFile, Status: 200, Headers: map[string]string{"X Total": "1"}},
+					},
+				}
+			},
+			wantError: true,
+		},
+		{
+			name: "Unknown cookie sameSite",
+			setupFn: func() Config {
+				return Config{
+					Endpoints: []Endpoint{
+						{Method: "GET", Path: "/test", JsonPath: jsonFile, Status: 200, Cookies: map[string]CookieConfig{"session": {Value: "abc", SameSite: "loose"}}},
+					},
+				}
+			},
+			wantError: true,
+		},
+		{
+			name: "Locale file not found",
+			setupFn: func() Config {
+				return Config{
+					Endpoints: []Endpoint{
+						{Method: "GET", Path: "/test", JsonPath: jsonFile, Status: 200, Locales: map[string]string{"ja": filepath.Join(tempDir, "notfound.ja.json")}},
+					},
+				}
+			},
+			wantError: true,
+		},
+		{
+			name: "Locales without fallback",
+			setupFn: func() Config {
+				return Config{
+					Localization: LocalizationConfig{DefaultLocale: "fr"},
+					Endpoints: []Endpoint{
+						{Method: "GET", Path: "/test", Status: 200, Locales: map[string]string{"ja": jsonFile}},
+					},
+				}
+			},
+			wantError: true,
+		},
+		{
+			name: "Negative cache TTL",
+			setupFn: func() Config {
+				return Config{
+					Cache: CacheConfig{TTL: -1},
+					Endpoints: []Endpoint{
+						{Method: "GET", Path: "/test", JsonPath: jsonFile, Status: 200},
+					},
+				}
+			},
+			wantError: true,
+		},
+		{
+			name: "Bad cache key header",
+			setupFn: func() Config {
+				return Config{
+					Endpoints: []Endpoint{
+						{Method: "GET", Path: "/test", JsonPath: jsonFile, Status: 200, Cache: &CacheConfig{KeyHeaders: []string{"X Tenant"}}},
+					},
+				}
+			},
+			wantError: true,
+		},
+		{
+			name: "Endpoint cache bounds",
+			setupFn: func() Config {
+				return Config{
+					Endpoints: []Endpoint{
+						{Method: "GET", Path: "/test", JsonPath: jsonFile, Status: 200, Cache: &CacheConfig{MaxEntries: 10}},
+					},
+				}
+			},
+			wantError: true,
+		},
+		{
+			name: "Invalid global CORS origin",
+			setupFn: func() Config {
+				return Config{
+					CORS: &CORSConfig{AllowOrigins: []string{"app.local/path"}},
+					Endpoints: []Endpoint{
+						{Method: "GET", Path: "/test", JsonPath: jsonFile, Status: 200},
+					},
+				}
+			},
+			wantError: true,
+		},
+		{
+			name: "Invalid endpoint CORS failure",
+			setupFn: func() Config {
+				return Config{
+					Endpoints: []Endpoint{
+						{Method: "GET", Path: "/test", JsonPath: jsonFile, Status: 200, CORS: &CORSConfig{Failure: "sometimes"}},
+					},
+				}
+			},
+			wantError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -218,46 +600,118 @@ func TestConfig_Reload(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 9090, cfg.Port)
 	assert.Equal(t, "info", cfg.LogLevel)
-}
+	assert.Equal(t, []ListenerConfig{{Address: ":9090"}}, cfg.ListenAddresses())
 
-func TestWatchConfig(t *testing.T) {
-	// This test is simplified as full testing would require more complex setup
-	tempDir, err := os.MkdirTemp("", "watch-test")
+	// Overrides survive the reload and are validated with the new file
+	cfg.Overrides = func(c *Config) {
+		c.Host = "127.0.0.1"
+		c.LogLevel = "debug"
+	}
+	err = cfg.Reload(configPath)
 	assert.NoError(t, err)
-	defer os.RemoveAll(tempDir)
+	assert.Equal(t, "127.0.0.1", cfg.Host)
+	assert.Equal(t, "debug", cfg.LogLevel)
+	assert.Equal(t, []ListenerConfig{{Address: "127.0.0.1:9090"}}, cfg.ListenAddresses())
 
-	// Create a test JSON file
+	cfg.Overrides = func(c *Config) {
+		c.Listeners = []ListenerConfig{{Address: "not an address"}}
+	}
+	err = cfg.Reload(configPath)
+	assert.ErrorIs(t, err, ErrInvalidListener)
+	assert.Empty(t, cfg.Listeners)
+}
+
+func TestLoadConfigWithOverrides(t *testing.T) {
+	tempDir := t.TempDir()
 	jsonFile := filepath.Join(tempDir, "test.json")
-	err = os.WriteFile(jsonFile, []byte(`{"message":"test"}`), 0644)
-	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(jsonFile, []byte(`{"ok": true}`), 0644))
 
-	// Create a config file
+	// The file sets an HTTP port and relies on the overrides to enable TLS
 	configPath := filepath.Join(tempDir, "config.json")
-	configContent := `{
-		"port": 8080,
+	content := `{
+		"tls": {"httpPort": 8080},
 		"endpoints": [
-			{
-				"method": "GET",
-				"status": 200,
-				"path": "/test",
-				"jsonPath": "` + jsonFile + `"
-			}
+			{"method": "GET", "status": 200, "path": "/test", "jsonPath": "` + jsonFile + `"}
 		]
 	}`
-	err = os.WriteFile(configPath, []byte(configContent), 0644)
-	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
 
-	// Load the config
-	cfg, err := LoadConfig(configPath)
+	_, err := LoadConfig(configPath)
+	assert.Error(t, err)
+
+	enableTLS := func(c *Config) { c.TLS.Auto = true }
+	cfg, err := LoadConfigWithOverrides(configPath, enableTLS)
 	assert.NoError(t, err)
+	assert.True(t, cfg.TLS.Enabled())
+	assert.Equal(t, 8080, cfg.TLS.HTTPPort)
 
-	// Setup a channel to receive notifications
-	reloadCh := make(chan bool, 1)
+	// Reloading validates after the overrides too
+	assert.NoError(t, cfg.Reload(configPath))
+	assert.True(t, cfg.TLS.Enabled())
+}
 
-	// Start watching
-	err = WatchConfig(configPath, cfg, reloadCh)
-	assert.NoError(t, err)
+func TestConfig_ResolveAuth(t *testing.T) {
+	global := &AuthConfig{APIKeys: []string{"global"}}
+	cfg := &Config{Auth: global}
+
+	// Endpoints inherit the global default
+	assert.Equal(t, global, cfg.ResolveAuth(Endpoint{Path: "/a"}))
+
+	// Endpoint settings take precedence
+	local := &AuthConfig{APIKeys: []string{"local"}}
+	assert.Equal(t, local, cfg.ResolveAuth(Endpoint{Path: "/b", Auth: local}))
+
+	// Endpoints can opt out of the global default
+	assert.Nil(t, cfg.ResolveAuth(Endpoint{Path: "/c", Auth: &AuthConfig{Disabled: true}}))
+
+	// No auth anywhere
+	assert.Nil(t, (&Config{}).ResolveAuth(Endpoint{Path: "/d"}))
+}
+
+func TestConfig_ResolveCache(t *testing.T) {
+	cfg := &Config{Cache: CacheConfig{TTL: 60, MaxEntries: 10, KeyHeaders: []string{"X-Tenant"}, IgnoreQuery: true}}
+
+	// Endpoints inherit the global settings and the bounds get defaults
+	cache := cfg.ResolveCache(&Endpoint{Path: "/a"})
+	assert.Equal(t, 60, cache.TTL)
+	assert.Equal(t, 10, cache.MaxEntries)
+	assert.Equal(t, int64(DefaultCacheMaxBytes), cache.MaxBytes)
+	assert.True(t, cache.IgnoreQuery)
+
+	// Endpoint settings take precedence, unset ones fall back
+	cache = cfg.ResolveCache(&Endpoint{Path: "/b", Cache: &CacheConfig{TTL: 5}})
+	assert.Equal(t, 5, cache.TTL)
+	assert.False(t, cache.IgnoreQuery)
+	assert.Equal(t, []string{"X-Tenant"}, cache.KeyHeaders)
+
+	// Endpoints can opt out of caching, or in when it is disabled globally
+	assert.True(t, cfg.ResolveCache(&Endpoint{Path: "/c", Cache: &CacheConfig{Disabled: true}}).Disabled)
+	cfg.Cache.Disabled = true
+	assert.False(t, cfg.ResolveCache(&Endpoint{Path: "/d", Cache: &CacheConfig{}}).Disabled)
+}
+
+func TestEndpoint_ActiveResponse(t *testing.T) {
+	ep := Endpoint{
+		Status:   200,
+		JsonPath: "ok.json",
+		Variants: map[string]ResponseVariant{
+			"error": {Status: 500, JsonPath: "error.json"},
+			"empty": {JsonPath: "empty.json"},
+		},
+	}
+
+	status, jsonPath := ep.ActiveResponse()
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "ok.json", jsonPath)
+
+	ep.Variant = "error"
+	status, jsonPath = ep.ActiveResponse()
+	assert.Equal(t, 500, status)
+	assert.Equal(t, "error.json", jsonPath)
 
-	// We can't easily test the file watching functionality in a unit test
-	// but we can at least verify the watcher is set up without errors
+	// Variants inherit unset fields
+	ep.Variant = "empty"
+	status, jsonPath = ep.ActiveResponse()
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "empty.json", jsonPath)
 }
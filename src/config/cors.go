@@ -0,0 +1,159 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Simulated CORS failures
+const (
+	// CORSFailureMissingOrigin leaves out Access-Control-Allow-Origin
+	CORSFailureMissingOrigin = "missingOrigin"
+	// CORSFailureWrongOrigin allows an origin other than the requesting one
+	CORSFailureWrongOrigin = "wrongOrigin"
+	// CORSFailureWildcardCredentials allows "*" together with credentials,
+	// which browsers reject for credentialed requests
+	CORSFailureWildcardCredentials = "wildcardCredentials"
+	// CORSFailurePreflight answers preflight requests with an error status
+	CORSFailurePreflight = "preflight"
+)
+
+// CORSWrongOrigin is the origin allowed when simulating CORSFailureWrongOrigin
+const CORSWrongOrigin = "https://cors-failure.invalid"
+
+// DefaultCORS is the policy applied when the config has no cors block: every
+// origin is allowed with "*", without credentials
+var DefaultCORS = CORSConfig{AllowOrigins: []string{"*"}}
+
+// CORSConfig represents the cross-origin policy of the server or an endpoint.
+// Origins are "*", exact origins ("https://app.local:8080") or origins with a
+// wildcard subdomain ("https://*.example.com").
+type CORSConfig struct {
+	Disabled         bool     `json:"disabled,omitempty"`
+	AllowOrigins     []string `json:"allowOrigins,omitempty"`
+	AllowOriginRegex string   `json:"allowOriginRegex,omitempty"`
+	AllowCredentials bool     `json:"allowCredentials,omitempty"`
+	// AllowHeaders defaults to the headers a preflight request asks for
+	AllowHeaders []string `json:"allowHeaders,omitempty"`
+	// AllowMethods defaults to the methods of the endpoints serving the path
+	AllowMethods  []string `json:"allowMethods,omitempty"`
+	ExposeHeaders []string `json:"exposeHeaders,omitempty"`
+	MaxAge        int      `json:"maxAge,omitempty"`
+	// Failure simulates a misconfigured server so that clients can test
+	// their handling of blocked requests
+	Failure       string `json:"failure,omitempty"`
+	FailureStatus int    `json:"failureStatus,omitempty"`
+}
+
+// originPatterns caches the compiled allowOriginRegex expressions
+var originPatterns sync.Map
+
+// validate checks that the origins and the simulated failure are usable
+func (c *CORSConfig) validate() error {
+	if c.Disabled {
+		return nil
+	}
+	for _, origin := range c.AllowOrigins {
+		if err := validateOrigin(origin); err != nil {
+			return err
+		}
+	}
+	if c.AllowOriginRegex != "" {
+		if _, err := regexp.Compile(c.AllowOriginRegex); err != nil {
+			return fmt.Errorf("bad allowOriginRegex: %v", err)
+		}
+	}
+	if c.MaxAge < 0 {
+		return errors.New("maxAge must not be negative")
+	}
+	switch c.Failure {
+	case "", CORSFailureMissingOrigin, CORSFailureWrongOrigin, CORSFailureWildcardCredentials, CORSFailurePreflight:
+	default:
+		return fmt.Errorf("unknown failure %q", c.Failure)
+	}
+	if c.FailureStatus != 0 && (c.FailureStatus < 400 || c.FailureStatus > 599) {
+		return fmt.Errorf("failure status %d is not an error status", c.FailureStatus)
+	}
+	return nil
+}
+
+// validateOrigin checks an allowed origin pattern
+func validateOrigin(origin string) error {
+	if origin == "*" {
+		return nil
+	}
+	u, err := url.Parse(strings.Replace(origin, "://*.", "://wildcard.", 1))
+	if err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+		return fmt.Errorf("bad origin %q: use scheme://host[:port]", origin)
+	}
+	if strings.Contains(u.Host, "*") {
+		return fmt.Errorf("bad origin %q: only a leading *. subdomain wildcard is supported", origin)
+	}
+	return nil
+}
+
+// AllowsOrigin reports whether a request Origin header value is allowed
+func (c *CORSConfig) AllowsOrigin(origin string) bool {
+	if origin == "" {
+		return false
+	}
+	for _, allowed := range c.AllowOrigins {
+		if allowed == "*" || strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return true
+		}
+		if scheme, domain, ok := strings.Cut(allowed, "://*."); ok {
+			rest, found := strings.CutPrefix(strings.ToLower(origin), strings.ToLower(scheme)+"://")
+			if found && strings.HasSuffix(rest, "."+strings.ToLower(domain)) {
+				return true
+			}
+		}
+	}
+	if c.AllowOriginRegex == "" {
+		return false
+	}
+
+	pattern, ok := originPatterns.Load(c.AllowOriginRegex)
+	if !ok {
+		compiled, err := regexp.Compile(c.AllowOriginRegex)
+		if err != nil {
+			return false
+		}
+		pattern, _ = originPatterns.LoadOrStore(c.AllowOriginRegex, compiled)
+	}
+	return pattern.(*regexp.Regexp).MatchString(origin)
+}
+
+// AllowsAnyOrigin reports whether the policy allows every origin
+func (c *CORSConfig) AllowsAnyOrigin() bool {
+	return slices.Contains(c.AllowOrigins, "*")
+}
+
+// ResolveCORS returns the CORS policy that applies to an endpoint, or nil if
+// cross-origin requests are not allowed. Endpoint settings take precedence
+// over the server policy, which defaults to DefaultCORS. A nil endpoint
+// resolves the server policy.
+func (c *Config) ResolveCORS(ep *Endpoint) *CORSConfig {
+	var cors *CORSConfig
+	if ep != nil {
+		cors = ep.CORS
+	}
+	if cors == nil {
+		c.mu.RLock()
+		cors = c.CORS
+		c.mu.RUnlock()
+	}
+	if cors == nil {
+		defaults := DefaultCORS
+		return &defaults
+	}
+
+	if cors.Disabled {
+		return nil
+	}
+	return cors
+}
@@ -0,0 +1,298 @@
+package config
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ErrEndpointNotFound is returned when no endpoint has the requested ID
+var ErrEndpointNotFound = errors.New("endpoint not found")
+
+// EndpointID derives a stable identifier from an endpoint's method, path, hosts and client certificate match
+func EndpointID(ep Endpoint) string {
+	key := ep.Method + " " + ep.Path
+	if len(ep.Hosts) > 0 {
+		key += " " + HostsKey(ep.Hosts)
+	}
+	if ep.ClientCert != nil {
+		key += " " + ep.ClientCert.String()
+	}
+	sum := sha1.Sum([]byte(key))
+	return hex.EncodeToString(sum[:6])
+}
+
+// GetEndpoint returns the endpoint with the given ID
+func (c *Config) GetEndpoint(id string) (Endpoint, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, ep := range c.Endpoints {
+		if ep.ID == id {
+			return ep, true
+		}
+	}
+	return Endpoint{}, false
+}
+
+// AddEndpoint validates and appends a new endpoint
+func (c *Config) AddEndpoint(ep Endpoint) (Endpoint, error) {
+	if ep.ID == "" {
+		ep.ID = EndpointID(ep)
+	}
+
+	err := c.updateEndpoints(func(endpoints []Endpoint) ([]Endpoint, error) {
+		return append(endpoints, ep), nil
+	})
+	return ep, err
+}
+
+// UpdateEndpoint validates and replaces the endpoint with the given ID
+func (c *Config) UpdateEndpoint(id string, ep Endpoint) (Endpoint, error) {
+	ep.ID = id
+
+	err := c.updateEndpoints(func(endpoints []Endpoint) ([]Endpoint, error) {
+		for i := range endpoints {
+			if endpoints[i].ID == id {
+				endpoints[i] = ep
+				return endpoints, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %s", ErrEndpointNotFound, id)
+	})
+	return ep, err
+}
+
+// DeleteEndpoint removes the endpoint with the given ID
+func (c *Config) DeleteEndpoint(id string) error {
+	return c.updateEndpoints(func(endpoints []Endpoint) ([]Endpoint, error) {
+		for i := range endpoints {
+			if endpoints[i].ID == id {
+				return append(endpoints[:i], endpoints[i+1:]...), nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %s", ErrEndpointNotFound, id)
+	})
+}
+
+// SetEndpointDisabled enables or disables the endpoint with the given ID
+func (c *Config) SetEndpointDisabled(id string, disabled bool) (Endpoint, error) {
+	var updated Endpoint
+
+	err := c.updateEndpoints(func(endpoints []Endpoint) ([]Endpoint, error) {
+		for i := range endpoints {
+			if endpoints[i].ID == id {
+				endpoints[i].Disabled = disabled
+				updated = endpoints[i]
+				return endpoints, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %s", ErrEndpointNotFound, id)
+	})
+	return updated, err
+}
+
+// SetEndpointVariant selects the response variant of the endpoint with the given ID.
+// An empty name restores the endpoint's default response.
+func (c *Config) SetEndpointVariant(id, variant string) (Endpoint, error) {
+	var updated Endpoint
+
+	err := c.updateEndpoints(func(endpoints []Endpoint) ([]Endpoint, error) {
+		for i := range endpoints {
+			if endpoints[i].ID == id {
+				endpoints[i].Variant = variant
+				updated = endpoints[i]
+				return endpoints, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %s", ErrEndpointNotFound, id)
+	})
+	return updated, err
+}
+
+// updateEndpoints applies a change to a copy of the endpoints, validates the
+// result and swaps it in, so readers never observe a partially applied change
+func (c *Config) updateEndpoints(change func([]Endpoint) ([]Endpoint, error)) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	endpoints := make([]Endpoint, len(c.Endpoints))
+	copy(endpoints, c.Endpoints)
+
+	endpoints, err := change(endpoints)
+	if err != nil {
+		return err
+	}
+
+	candidate := &Config{
+		OIDC:         c.OIDC,
+		Auth:         c.Auth,
+		RateLimit:    c.RateLimit,
+		CORS:         c.CORS,
+		Localization: c.Localization,
+		Cache:        c.Cache,
+		Metrics:      c.Metrics,
+		Tracing:      c.Tracing,
+		TLS:          c.TLS,
+		Listeners:    c.Listeners,
+		Endpoints:    endpoints,
+	}
+	if err := candidate.Validate(); err != nil {
+		return err
+	}
+
+	// Persist before swapping in, so that concurrent changes are written in order
+	if c.persistPath != "" {
+		if err := c.saveEndpoints(c.persistPath, endpoints); err != nil {
+			return fmt.Errorf("%w: %v", ErrPersist, err)
+		}
+	}
+
+	c.Endpoints = endpoints
+	c.generation++
+	return nil
+}
+
+// SetPersistPath makes every endpoint change be written to the config file at
+// path before it is applied. An empty path stops persisting.
+func (c *Config) SetPersistPath(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.persistPath = path
+}
+
+// SaveEndpoints writes the current endpoints back to the config file at path
+func (c *Config) SaveEndpoints(path string) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.saveEndpoints(path, c.Endpoints)
+}
+
+// saveEndpoints writes endpoints to the config file at path. Other settings in
+// the file are preserved, so command line overrides and defaults are never
+// persisted. Endpoints of virtual hosts go back to their virtual host and
+// derived IDs are left out. The caller must hold c.mu.
+func (c *Config) saveEndpoints(path string, endpoints []Endpoint) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("error reading config file: %w", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading config file: %w", err)
+	}
+
+	var document map[string]json.RawMessage
+	if err := json.Unmarshal(data, &document); err != nil {
+		return fmt.Errorf("error parsing config file: %w", err)
+	}
+
+	topLevel, virtualHosts := c.groupEndpoints(endpoints)
+	encoded, err := json.Marshal(topLevel)
+	if err != nil {
+		return fmt.Errorf("error encoding endpoints: %w", err)
+	}
+	document["endpoints"] = encoded
+	if len(virtualHosts) > 0 {
+		encoded, err := json.Marshal(virtualHosts)
+		if err != nil {
+			return fmt.Errorf("error encoding virtual hosts: %w", err)
+		}
+		document["virtualHosts"] = encoded
+	}
+
+	output, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding config file: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(output, '\n'), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("error writing config file: %w", err)
+	}
+	return nil
+}
+
+// groupEndpoints splits endpoints into those of the top-level list and those
+// of the virtual hosts of the config file, in the form they are written in.
+// An endpoint belongs to the first virtual host with the same hosts.
+func (c *Config) groupEndpoints(endpoints []Endpoint) ([]Endpoint, []VirtualHost) {
+	virtualHosts := make([]VirtualHost, len(c.virtualHostGroups))
+	groups := make(map[string]int)
+	for i, hosts := range c.virtualHostGroups {
+		virtualHosts[i] = VirtualHost{Hosts: hosts, Endpoints: []Endpoint{}}
+		if _, ok := groups[HostsKey(hosts)]; !ok {
+			groups[HostsKey(hosts)] = i
+		}
+	}
+
+	topLevel := []Endpoint{}
+	for _, ep := range endpoints {
+		if ep.ID == EndpointID(ep) {
+			ep.ID = ""
+		}
+		i, ok := groups[HostsKey(ep.Hosts)]
+		if !ok || len(ep.Hosts) == 0 {
+			topLevel = append(topLevel, ep)
+			continue
+		}
+		ep.Hosts = nil
+		virtualHosts[i].Endpoints = append(virtualHosts[i].Endpoints, ep)
+	}
+	return topLevel, virtualHosts
+}
+
+// ResponseFiles returns the absolute paths of the response files referenced
+// by the endpoints, their variants and their locales
+func (c *Config) ResponseFiles() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	add := func(path string) {
+		if path == "" {
+			return
+		}
+		if abs, err := filepath.Abs(path); err == nil {
+			seen[abs] = true
+		}
+	}
+	for _, ep := range c.Endpoints {
+		if ep.Folder != "" {
+			continue
+		}
+		add(ep.JsonPath)
+		for _, variant := range ep.Variants {
+			add(variant.JsonPath)
+		}
+		for _, file := range ep.Locales {
+			add(file)
+		}
+	}
+
+	files := make([]string, 0, len(seen))
+	for path := range seen {
+		files = append(files, path)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// ValidateResponseFile checks that a response file exists and, for .json
+// files, still holds valid JSON
+func ValidateResponseFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrJSONFileNotFound, err)
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") && !json.Valid(content) {
+		return fmt.Errorf("%w: %s is not valid JSON", ErrInvalidResponse, path)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_EndpointMutations(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "endpoints-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	jsonFile := filepath.Join(tempDir, "test.json")
+	err = os.WriteFile(jsonFile, []byte(`{"message":"test"}`), 0644)
+	assert.NoError(t, err)
+
+	cfg := &Config{
+		Endpoints: []Endpoint{
+			{ID: "first", Method: "GET", Path: "/test", JsonPath: jsonFile, Status: 200},
+		},
+	}
+
+	// Add assigns a stable ID
+	added, err := cfg.AddEndpoint(Endpoint{Method: "POST", Path: "/test", JsonPath: jsonFile, Status: 201})
+	assert.NoError(t, err)
+	assert.Equal(t, EndpointID(Endpoint{Method: "POST", Path: "/test"}), added.ID)
+	assert.Len(t, cfg.GetEndpoints(), 2)
+
+	// Invalid changes are rejected without modifying the config
+	_, err = cfg.AddEndpoint(Endpoint{Method: "GET", Path: "/test", JsonPath: jsonFile, Status: 200})
+	assert.ErrorIs(t, err, ErrDuplicateEndpoint)
+	_, err = cfg.AddEndpoint(Endpoint{Method: "GET", Path: "/other", JsonPath: filepath.Join(tempDir, "missing.json")})
+	assert.ErrorIs(t, err, ErrJSONFileNotFound)
+	assert.Len(t, cfg.GetEndpoints(), 2)
+
+	// Update
+	updated, err := cfg.UpdateEndpoint("first", Endpoint{Method: "GET", Path: "/test", JsonPath: jsonFile, Status: 202})
+	assert.NoError(t, err)
+	assert.Equal(t, "first", updated.ID)
+	ep, ok := cfg.GetEndpoint("first")
+	assert.True(t, ok)
+	assert.Equal(t, 202, ep.Status)
+
+	_, err = cfg.UpdateEndpoint("missing", Endpoint{Method: "GET", Path: "/x"})
+	assert.ErrorIs(t, err, ErrEndpointNotFound)
+
+	// Disable
+	disabled, err := cfg.SetEndpointDisabled("first", true)
+	assert.NoError(t, err)
+	assert.True(t, disabled.Disabled)
+
+	// Delete
+	assert.NoError(t, cfg.DeleteEndpoint(added.ID))
+	assert.Len(t, cfg.GetEndpoints(), 1)
+	assert.ErrorIs(t, cfg.DeleteEndpoint(added.ID), ErrEndpointNotFound)
+}
+
+func TestConfig_SaveEndpoints(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "save-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	jsonFile := filepath.Join(tempDir, "test.json")
+	err = os.WriteFile(jsonFile, []byte(`{"message":"test"}`), 0644)
+	assert.NoError(t, err)
+
+	configPath := filepath.Join(tempDir, "config.json")
+	configContent := `{
+		"port": 8080,
+		"endpoints": [
+			{"method": "GET", "status": 200, "path": "/test", "jsonPath": "` + jsonFile + `"}
+		]
+	}`
+	err = os.WriteFile(configPath, []byte(configContent), 0644)
+	assert.NoError(t, err)
+
+	cfg, err := LoadConfig(configPath)
+	assert.NoError(t, err)
+
+	// Runtime overrides must not leak into the file
+	cfg.Port = 9999
+	_, err = cfg.AddEndpoint(Endpoint{Method: "GET", Path: "/new", JsonPath: jsonFile, Status: 200})
+	assert.NoError(t, err)
+	assert.NoError(t, cfg.SaveEndpoints(configPath))
+
+	data, err := os.ReadFile(configPath)
+	assert.NoError(t, err)
+
+	var document map[string]any
+	assert.NoError(t, json.Unmarshal(data, &document))
+	assert.Equal(t, float64(8080), document["port"])
+	assert.NotContains(t, document, "logLevel")
+
+	reloaded, err := LoadConfig(configPath)
+	assert.NoError(t, err)
+	assert.Len(t, reloaded.Endpoints, 2)
+	assert.Equal(t, "/new", reloaded.Endpoints[1].Path)
+}
+
+func TestConfig_PersistPath(t *testing.T) {
+	tempDir := t.TempDir()
+	jsonFile := filepath.Join(tempDir, "test.json")
+	assert.NoError(t, os.WriteFile(jsonFile, []byte(`{"message":"test"}`), 0644))
+
+	configPath := filepath.Join(tempDir, "config.json")
+	configContent := `{"endpoints": [{"method": "GET", "status": 200, "path": "/test", "jsonPath": "` + jsonFile + `"}]}`
+	assert.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+
+	cfg, err := LoadConfig(configPath)
+	assert.NoError(t, err)
+	cfg.SetPersistPath(configPath)
+
+	// Every change is written as part of the update
+	added, err := cfg.AddEndpoint(Endpoint{Method: "GET", Path: "/new", JsonPath: jsonFile, Status: 200})
+	assert.NoError(t, err)
+	reloaded, err := LoadConfig(configPath)
+	assert.NoError(t, err)
+	assert.Len(t, reloaded.Endpoints, 2)
+	assert.Equal(t, added.ID, reloaded.Endpoints[1].ID)
+
+	// A change that cannot be written is not applied
+	assert.NoError(t, os.Remove(configPath))
+	_, err = cfg.AddEndpoint(Endpoint{Method: "GET", Path: "/other", JsonPath: jsonFile, Status: 200})
+	assert.ErrorIs(t, err, ErrPersist)
+	assert.Len(t, cfg.GetEndpoints(), 2)
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// SameSite values of a cookie
+const (
+	SameSiteLax    = "lax"
+	SameSiteStrict = "strict"
+	SameSiteNone   = "none"
+)
+
+// headerToken matches valid header and cookie names
+var headerToken = regexp.MustCompile("^[!#$%&'*+\\-.^_`|~0-9A-Za-z]+$")
+
+// CookieConfig represents a cookie set by an endpoint's response. A negative
+// MaxAge deletes the cookie.
+type CookieConfig struct {
+	Value    string `json:"value"`
+	Path     string `json:"path,omitempty"`
+	Domain   string `json:"domain,omitempty"`
+	MaxAge   int    `json:"maxAge,omitempty"`
+	Secure   bool   `json:"secure,omitempty"`
+	HttpOnly bool   `json:"httpOnly,omitempty"`
+	SameSite string `json:"sameSite,omitempty"`
+}
+
+// validateHeaders checks the names of an endpoint's response headers and cookies
+func (ep Endpoint) validateHeaders() error {
+	for name, value := range ep.Headers {
+		if !headerToken.MatchString(name) {
+			return fmt.Errorf("bad header name %q", name)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("header %s contains a line break", name)
+		}
+	}
+	for name, cookie := range ep.Cookies {
+		if !headerToken.MatchString(name) {
+			return fmt.Errorf("bad cookie name %q", name)
+		}
+		if strings.ContainsAny(cookie.Value, "\";, \r\n") {
+			return fmt.Errorf("cookie %s has characters not allowed in cookie values", name)
+		}
+		switch strings.ToLower(cookie.SameSite) {
+		case "", SameSiteLax, SameSiteStrict, SameSiteNone:
+		default:
+			return fmt.Errorf("unknown sameSite %q for cookie %s", cookie.SameSite, name)
+		}
+	}
+	return nil
+}
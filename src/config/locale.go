@@ -0,0 +1,114 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultLocaleParam is the query parameter that selects a locale when none is configured
+const DefaultLocaleParam = "lang"
+
+// languageTag matches well-formed BCP 47 language tags such as "en" or "zh-Hant-TW"
+var languageTag = regexp.MustCompile(`^[A-Za-z]{1,8}(-[A-Za-z0-9]{1,8})*$`)
+
+// LocalizationConfig represents the server-wide settings of localized responses
+type LocalizationConfig struct {
+	// DefaultLocale is served when no locale of an endpoint matches the request
+	DefaultLocale string `json:"defaultLocale,omitempty"`
+	// QueryParam selects a locale, overriding Accept-Language
+	QueryParam string `json:"queryParam,omitempty"`
+}
+
+// validateLocales checks an endpoint's locale tags and that a response is
+// left when no locale matches. fallback is the server's default locale.
+func (ep Endpoint) validateLocales(fallback string) error {
+	for tag := range ep.Locales {
+		if !languageTag.MatchString(tag) {
+			return fmt.Errorf("bad locale %q", tag)
+		}
+	}
+	if ep.DefaultLocale != "" {
+		if _, ok := ep.Locales[ep.DefaultLocale]; !ok {
+			return fmt.Errorf("default locale %s has no response file", ep.DefaultLocale)
+		}
+	}
+	hasDefault := ep.JsonPath != "" || ep.Body != "" || ep.BodyBase64 != ""
+	if len(ep.Locales) > 0 && !hasDefault && ep.DefaultLocale == "" {
+		if _, ok := ep.Locales[fallback]; !ok {
+			return errors.New("no default response or default locale to fall back to")
+		}
+	}
+	return nil
+}
+
+// MatchLocale selects the locale of an endpoint's response for a language
+// priority list with the lookup scheme of RFC 4647: each range is shortened
+// subtag by subtag until it names a locale, so "de-CH" matches "de". Without
+// a match the endpoint's default locale or else fallback is chosen. It
+// returns "" when the endpoint's default response applies.
+func (ep Endpoint) MatchLocale(ranges []string, fallback string) string {
+	if len(ep.Locales) == 0 {
+		return ""
+	}
+
+	for _, r := range ranges {
+		for r != "" && r != "*" {
+			for tag := range ep.Locales {
+				if strings.EqualFold(tag, r) {
+					return tag
+				}
+			}
+			r = truncateRange(r)
+		}
+	}
+
+	if ep.DefaultLocale != "" {
+		return ep.DefaultLocale
+	}
+	if _, ok := ep.Locales[fallback]; ok {
+		return fallback
+	}
+	return ""
+}
+
+// truncateRange removes the last subtag of a language range, together with a
+// single-character subtag such as the "x" of private use subtags left at the end
+func truncateRange(r string) string {
+	i := strings.LastIndexByte(r, '-')
+	if i < 0 {
+		return ""
+	}
+	r = r[:i]
+	if j := strings.LastIndexByte(r, '-'); j >= 0 && len(r)-j == 2 {
+		r = r[:j]
+	}
+	return r
+}
+
+// LocalizedResponse returns the status and response file for a locale chosen
+// by MatchLocale, and whether the locale's file is served. A variant with its
+// own file takes precedence over the locale.
+func (ep Endpoint) LocalizedResponse(locale string) (status int, jsonPath string, localized bool) {
+	status, jsonPath = ep.ActiveResponse()
+	if variant, ok := ep.Variants[ep.Variant]; ok && variant.JsonPath != "" {
+		return status, jsonPath, false
+	}
+	if file, ok := ep.Locales[locale]; ok {
+		return status, file, true
+	}
+	return status, jsonPath, false
+}
+
+// GetLocalization returns the localization settings in a thread-safe manner
+func (c *Config) GetLocalization() LocalizationConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	localization := c.Localization
+	if localization.QueryParam == "" {
+		localization.QueryParam = DefaultLocaleParam
+	}
+	return localization
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndpoint_MatchLocale(t *testing.T) {
+	ep := Endpoint{Locales: map[string]string{
+		"en":      "users.en.json",
+		"ja":      "users.ja.json",
+		"zh-Hant": "users.zh-Hant.json",
+		"de-CH":   "users.de-CH.json",
+	}}
+
+	tests := []struct {
+		name     string
+		ranges   []string
+		fallback string
+		want     string
+	}{
+		{"exact", []string{"ja"}, "", "ja"},
+		{"case insensitive", []string{"ZH-hant"}, "", "zh-Hant"},
+		{"truncated range", []string{"en-GB"}, "", "en"},
+		{"private use subtags", []string{"zh-Hant-CN-x-private1-private2"}, "", "zh-Hant"},
+		{"first matching range", []string{"fr", "de-CH-1996", "en"}, "", "de-CH"},
+		{"ranges are never extended", []string{"de"}, "en", "en"},
+		{"wildcard", []string{"*"}, "ja", "ja"},
+		{"no match without fallback", []string{"fr"}, "", ""},
+		{"fallback without locale", []string{"fr"}, "ko", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ep.MatchLocale(tt.ranges, tt.fallback))
+		})
+	}
+
+	// The endpoint's default locale overrides the server's
+	ep.DefaultLocale = "ja"
+	assert.Equal(t, "ja", ep.MatchLocale([]string{"fr"}, "en"))
+
+	assert.Empty(t, Endpoint{}.MatchLocale([]string{"en"}, "en"))
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Kinds of path pattern segments
+const (
+	SegmentStatic = iota
+	SegmentParam
+	SegmentWildcard
+)
+
+// paramName matches the names of parameters and catch-alls
+var paramName = regexp.MustCompile(`^\w*$`)
+
+// PathSegment is one "/"-separated part of an endpoint path pattern
+type PathSegment struct {
+	Kind int
+	// Value is the text of a static segment or the name of a parameter
+	Value string
+	// Pattern constrains the values a parameter matches, e.g. :id(\d+)
+	Pattern *regexp.Regexp
+	// Optional parameters also match paths that leave the segment out
+	Optional bool
+}
+
+// ParsePath splits an endpoint path into segments. Besides static text it
+// supports :name parameters, :name(regex) parameters whose whole value must
+// match the regular expression, optional :name? parameters and a final *name
+// catch-all matching the rest of the path.
+func ParsePath(path string) ([]PathSegment, error) {
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	segments := make([]PathSegment, 0, len(parts))
+
+	for i, part := range parts {
+		switch {
+		case strings.HasPrefix(part, ":"):
+			segment, err := parseParam(part[1:])
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, segment)
+
+		case strings.HasPrefix(part, "*"):
+			name := part[1:]
+			if !paramName.MatchString(name) {
+				return nil, fmt.Errorf("bad catch-all name %q", name)
+			}
+			if i != len(parts)-1 {
+				return nil, fmt.Errorf("catch-all %s must be the last segment", part)
+			}
+			segments = append(segments, PathSegment{Kind: SegmentWildcard, Value: name})
+
+		default:
+			segments = append(segments, PathSegment{Kind: SegmentStatic, Value: part})
+		}
+	}
+	return segments, nil
+}
+
+// parseParam parses a parameter segment without its leading colon
+func parseParam(text string) (PathSegment, error) {
+	segment := PathSegment{Kind: SegmentParam}
+
+	if rest, ok := strings.CutSuffix(text, "?"); ok {
+		segment.Optional = true
+		text = rest
+	}
+
+	if open := strings.IndexByte(text, '('); open >= 0 {
+		if !strings.HasSuffix(text, ")") {
+			return segment, fmt.Errorf("unterminated constraint in :%s", text)
+		}
+		pattern, err := regexp.Compile(`^(?:` + text[open+1:len(text)-1] + `)$`)
+		if err != nil {
+			return segment, fmt.Errorf("bad constraint in :%s: %v", text, err)
+		}
+		segment.Pattern = pattern
+		text = text[:open]
+	}
+
+	if text == "" || !paramName.MatchString(text) {
+		return segment, fmt.Errorf("bad parameter name %q", text)
+	}
+	segment.Value = text
+	return segment, nil
+}
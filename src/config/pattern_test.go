@@ -0,0 +1,46 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePath(t *testing.T) {
+	segments, err := ParsePath(`/users/:id(\d+)/posts/:postId?/*rest`)
+	assert.NoError(t, err)
+	assert.Len(t, segments, 5)
+
+	assert.Equal(t, PathSegment{Kind: SegmentStatic, Value: "users"}, segments[0])
+
+	assert.Equal(t, SegmentParam, segments[1].Kind)
+	assert.Equal(t, "id", segments[1].Value)
+	assert.True(t, segments[1].Pattern.MatchString("42"))
+	assert.False(t, segments[1].Pattern.MatchString("42a"))
+	assert.False(t, segments[1].Optional)
+
+	assert.Equal(t, PathSegment{Kind: SegmentStatic, Value: "posts"}, segments[2])
+	assert.Equal(t, PathSegment{Kind: SegmentParam, Value: "postId", Optional: true}, segments[3])
+	assert.Equal(t, PathSegment{Kind: SegmentWildcard, Value: "rest"}, segments[4])
+
+	// The root path is a single empty static segment
+	segments, err = ParsePath("/")
+	assert.NoError(t, err)
+	assert.Equal(t, []PathSegment{{Kind: SegmentStatic, Value: ""}}, segments)
+}
+
+func TestParsePath_Invalid(t *testing.T) {
+	for _, path := range []string{
+		"/files/*rest/meta",
+		"/users/:",
+		"/users/:id(",
+		"/users/:id([)",
+		"/users/:my-id",
+		"/files/*my-rest",
+	} {
+		t.Run(path, func(t *testing.T) {
+			_, err := ParsePath(path)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+)
+
+// VirtualHost groups endpoints that are only served for the given hosts.
+// Hosts are exact names ("users.api.local") or wildcards ("*.api.local").
+type VirtualHost struct {
+	Hosts     []string   `json:"hosts"`
+	Endpoints []Endpoint `json:"endpoints"`
+}
+
+// exactHostScore ranks exact host matches above any wildcard
+const exactHostScore = 1 << 16
+
+// NormalizeHost strips the port, a trailing dot and case from a Host header value
+func NormalizeHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimPrefix(strings.TrimSuffix(host, "]"), "[")
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
+
+// validateHostPattern checks an exact or wildcard host name
+func validateHostPattern(pattern string) error {
+	name := strings.TrimPrefix(pattern, "*.")
+	if name == "" || strings.Contains(name, "*") {
+		return fmt.Errorf("bad host %q: use a name or a leading *. wildcard", pattern)
+	}
+	if strings.Contains(name, ":") && net.ParseIP(name) == nil {
+		return fmt.Errorf("bad host %q: hosts must not include a port", pattern)
+	}
+	return nil
+}
+
+// hostScore reports whether a host pattern matches a normalized host and how
+// specific the match is. Exact names beat wildcards, longer wildcards beat shorter ones.
+func hostScore(pattern, host string) (int, bool) {
+	pattern = NormalizeHost(pattern)
+	if suffix, ok := strings.CutPrefix(pattern, "*"); ok {
+		if strings.HasSuffix(host, suffix) && len(host) > len(suffix) {
+			return len(suffix), true
+		}
+		return 0, false
+	}
+	if pattern == host {
+		return exactHostScore + len(pattern), true
+	}
+	return 0, false
+}
+
+// MatchHosts reports whether any of the host patterns matches a normalized
+// host and returns the score of the most specific match
+func MatchHosts(patterns []string, host string) (int, bool) {
+	best, matched := 0, false
+	for _, pattern := range patterns {
+		if score, ok := hostScore(pattern, host); ok && (!matched || score > best) {
+			best, matched = score, true
+		}
+	}
+	return best, matched
+}
+
+// HostsKey identifies the virtual host of a host list, ignoring case and order
+func HostsKey(hosts []string) string {
+	normalized := make([]string, 0, len(hosts))
+	for _, h := range hosts {
+		normalized = append(normalized, NormalizeHost(h))
+	}
+	slices.Sort(normalized)
+	return strings.Join(normalized, ",")
+}
+
+// validateHosts checks the host patterns of an endpoint
+func validateHosts(hosts []string) error {
+	for _, h := range hosts {
+		if err := validateHostPattern(h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flattenVirtualHosts moves the endpoints of virtual hosts into the endpoint
+// list, tagging each with its hosts
+func (c *Config) flattenVirtualHosts() error {
+	for _, vh := range c.VirtualHosts {
+		if len(vh.Hosts) == 0 {
+			return errors.New("virtual host without hosts")
+		}
+		c.virtualHostGroups = append(c.virtualHostGroups, vh.Hosts)
+		for _, ep := range vh.Endpoints {
+			if len(ep.Hosts) == 0 {
+				ep.Hosts = vh.Hosts
+			}
+			c.Endpoints = append(c.Endpoints, ep)
+		}
+	}
+	c.VirtualHosts = nil
+	return nil
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeHost(t *testing.T) {
+	assert.Equal(t, "users.api.local", NormalizeHost("Users.API.local:8080"))
+	assert.Equal(t, "users.api.local", NormalizeHost("users.api.local."))
+	assert.Equal(t, "::1", NormalizeHost("[::1]:3000"))
+	assert.Equal(t, "127.0.0.1", NormalizeHost("127.0.0.1"))
+	assert.Equal(t, "", NormalizeHost(""))
+}
+
+func TestLoadConfig_VirtualHosts(t *testing.T) {
+	tempDir := t.TempDir()
+	jsonFile := filepath.Join(tempDir, "status.json")
+	assert.NoError(t, os.WriteFile(jsonFile, []byte(`{"ok":true}`), 0644))
+
+	configPath := filepath.Join(tempDir, "config.json")
+	configContent := `{
+		"virtualHosts": [
+			{
+				"hosts": ["users.api.local"],
+				"endpoints": [{"method": "GET", "status": 200, "path": "/status", "jsonPath": "` + jsonFile + `"}]
+			},
+			{
+				"hosts": ["*.api.local"],
+				"endpoints": [{"method": "GET", "status": 200, "path": "/status", "jsonPath": "` + jsonFile + `"}]
+			}
+		],
+		"endpoints": [{"method": "GET", "status": 200, "path": "/status", "jsonPath": "` + jsonFile + `"}]
+	}`
+	assert.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+
+	cfg, err := LoadConfig(configPath)
+	assert.NoError(t, err)
+	assert.Nil(t, cfg.VirtualHosts)
+
+	endpoints := cfg.GetEndpoints()
+	assert.Len(t, endpoints, 3)
+	assert.Empty(t, endpoints[0].Hosts)
+	assert.Equal(t, []string{"users.api.local"}, endpoints[1].Hosts)
+	assert.Equal(t, []string{"*.api.local"}, endpoints[2].Hosts)
+
+	// Same method and path on different hosts get different IDs
+	assert.NotEqual(t, endpoints[0].ID, endpoints[1].ID)
+	assert.NotEqual(t, endpoints[1].ID, endpoints[2].ID)
+
+	// Persisting keeps the virtual hosts and leaves out derived IDs
+	_, err = cfg.AddEndpoint(Endpoint{Method: "GET", Status: 200, Path: "/users", JsonPath: jsonFile, Hosts: []string{"users.api.local"}})
+	assert.NoError(t, err)
+	endpoints = cfg.GetEndpoints()
+	assert.NoError(t, cfg.SaveEndpoints(configPath))
+	data, err := os.ReadFile(configPath)
+	assert.NoError(t, err)
+	var document struct {
+		Endpoints    []map[string]any `json:"endpoints"`
+		VirtualHosts []struct {
+			Hosts     []string         `json:"hosts"`
+			Endpoints []map[string]any `json:"endpoints"`
+		} `json:"virtualHosts"`
+	}
+	assert.NoError(t, json.Unmarshal(data, &document))
+	assert.Len(t, document.Endpoints, 1)
+	assert.NotContains(t, document.Endpoints[0], "id")
+	assert.Len(t, document.VirtualHosts, 2)
+	assert.Equal(t, []string{"users.api.local"}, document.VirtualHosts[0].Hosts)
+	assert.Len(t, document.VirtualHosts[0].Endpoints, 2)
+	assert.NotContains(t, document.VirtualHosts[0].Endpoints[0], "hosts")
+	assert.Len(t, document.VirtualHosts[1].Endpoints, 1)
+
+	reloaded, err := LoadConfig(configPath)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, endpoints, reloaded.GetEndpoints())
+}
+
+func TestValidateHosts(t *testing.T) {
+	assert.NoError(t, validateHosts([]string{"users.api.local", "*.api.local", "127.0.0.1", "::1"}))
+	assert.Error(t, validateHosts([]string{""}))
+	assert.Error(t, validateHosts([]string{"*"}))
+	assert.Error(t, validateHosts([]string{"users.*.local"}))
+	assert.Error(t, validateHosts([]string{"users.api.local:8080"}))
+}
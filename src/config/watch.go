@@ -0,0 +1,291 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/tkc/go-json-server/src/logger"
+)
+
+// watchDebounce is how long a burst of file events has to settle before reloading
+const watchDebounce = 100 * time.Millisecond
+
+// configWatcher tracks the config file across in-place writes, rename-replace
+// saves and symlink swaps
+type configWatcher struct {
+	// path is the absolute config path and target the file it resolves to
+	path   string
+	target string
+
+	watcher *fsnotify.Watcher
+	dirs    map[string]bool
+	log     *logger.Logger
+}
+
+// WatchConfig reloads config when the file at configPath changes and reports
+// every reload attempt on reloadCh. Editors that save by renaming a new file
+// over the old one and symlink swaps such as Kubernetes ConfigMap updates are
+// detected as well as in-place writes.
+func WatchConfig(configPath string, config *Config, reloadCh chan<- bool, log *logger.Logger) error {
+	path, err := filepath.Abs(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve config path: %w", err)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %w", err)
+	}
+
+	w := &configWatcher{
+		path:    path,
+		watcher: watcher,
+		dirs:    make(map[string]bool),
+		log:     log,
+	}
+	if err := w.watchDirs(); err != nil {
+		watcher.Close()
+		return err
+	}
+
+	go w.run(config, reloadCh)
+	return nil
+}
+
+// resolve returns the file the config path points to after following symlinks
+func (w *configWatcher) resolve() string {
+	target, err := filepath.EvalSymlinks(w.path)
+	if err != nil {
+		return w.path
+	}
+	return target
+}
+
+// watchDirs watches the directories holding the config path and its symlink
+// target. Watching directories rather than the file itself keeps working when
+// the file is replaced.
+func (w *configWatcher) watchDirs() error {
+	w.target = w.resolve()
+	wanted := map[string]bool{
+		filepath.Dir(w.path):   true,
+		filepath.Dir(w.target): true,
+	}
+
+	for dir := range wanted {
+		if w.dirs[dir] {
+			continue
+		}
+		if err := w.watcher.Add(dir); err != nil {
+			return fmt.Errorf("failed to watch directory %s: %w", dir, err)
+		}
+		w.dirs[dir] = true
+	}
+	for dir := range w.dirs {
+		if !wanted[dir] {
+			// The directory may already be gone, as after a ConfigMap update
+			w.watcher.Remove(dir)
+			delete(w.dirs, dir)
+		}
+	}
+	return nil
+}
+
+// relevant reports whether an event may have changed the config contents
+func (w *configWatcher) relevant(event fsnotify.Event) bool {
+	name := filepath.Clean(event.Name)
+	if name == w.path || name == w.target {
+		return true
+	}
+	// A symlink on the way to the file was swapped
+	return w.resolve() != w.target
+}
+
+// run reloads the config once events stop arriving for watchDebounce
+func (w *configWatcher) run(config *Config, reloadCh chan<- bool) {
+	defer w.watcher.Close()
+
+	var timer *time.Timer
+	var fire <-chan time.Time
+
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if !w.relevant(event) {
+				continue
+			}
+			if timer != nil {
+				timer.Stop()
+			}
+			timer = time.NewTimer(watchDebounce)
+			fire = timer.C
+
+		case <-fire:
+			fire = nil
+
+			// The file is being replaced; the event that creates it triggers the reload
+			if _, err := os.Stat(w.path); err != nil {
+				w.log.Debug("Config file missing, waiting for it to be recreated", map[string]any{"path": w.path})
+				continue
+			}
+			if err := w.watchDirs(); err != nil {
+				w.log.Warn("Failed to follow config file", map[string]any{"path": w.path, "error": err.Error()})
+			}
+
+			w.log.Info("Config file changed, reloading", map[string]any{"path": w.path})
+			err := config.Reload(w.path)
+			if err != nil {
+				w.log.Error("Failed to reload config", map[string]any{"path": w.path, "error": err.Error()})
+			}
+			if reloadCh != nil {
+				reloadCh <- err == nil
+			}
+
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			w.log.Error("Error watching config", map[string]any{"path": w.path, "error": err.Error()})
+		}
+	}
+}
+
+// FileWatcher reports changes to the response files referenced by a config
+type FileWatcher struct {
+	config   *Config
+	watcher  *fsnotify.Watcher
+	log      *logger.Logger
+	onChange func(path string)
+
+	mu    sync.Mutex
+	files map[string]bool
+	dirs  map[string]bool
+}
+
+// WatchResponseFiles watches the response files of the config's endpoints and
+// calls onChange with the absolute path of every file that changed. Changed
+// files are re-validated and problems are logged. Call Refresh after the
+// endpoints change so that newly referenced files are watched too.
+func WatchResponseFiles(config *Config, log *logger.Logger, onChange func(path string)) (*FileWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file watcher: %w", err)
+	}
+
+	fw := &FileWatcher{
+		config:   config,
+		watcher:  watcher,
+		log:      log,
+		onChange: onChange,
+		files:    make(map[string]bool),
+		dirs:     make(map[string]bool),
+	}
+	if err := fw.Refresh(); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	go fw.run()
+	return fw, nil
+}
+
+// Refresh watches the response files currently referenced by the config and
+// stops watching directories that no longer hold any
+func (fw *FileWatcher) Refresh() error {
+	files := make(map[string]bool)
+	dirs := make(map[string]bool)
+	for _, path := range fw.config.ResponseFiles() {
+		files[path] = true
+		dirs[filepath.Dir(path)] = true
+	}
+
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	fw.files = files
+
+	var firstErr error
+	for dir := range dirs {
+		if fw.dirs[dir] {
+			continue
+		}
+		if err := fw.watcher.Add(dir); err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to watch directory %s: %w", dir, err)
+			}
+			continue
+		}
+		fw.dirs[dir] = true
+	}
+	for dir := range fw.dirs {
+		if !dirs[dir] {
+			fw.watcher.Remove(dir)
+			delete(fw.dirs, dir)
+		}
+	}
+	return firstErr
+}
+
+// Close stops watching
+func (fw *FileWatcher) Close() error {
+	return fw.watcher.Close()
+}
+
+// watched reports whether path is one of the response files
+func (fw *FileWatcher) watched(path string) bool {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	return fw.files[path]
+}
+
+// run collects changed files and reports them once events stop arriving for watchDebounce
+func (fw *FileWatcher) run() {
+	var timer *time.Timer
+	var fire <-chan time.Time
+	pending := make(map[string]bool)
+
+	for {
+		select {
+		case event, ok := <-fw.watcher.Events:
+			if !ok {
+				return
+			}
+			path := filepath.Clean(event.Name)
+			if !fw.watched(path) {
+				continue
+			}
+			pending[path] = true
+			if timer != nil {
+				timer.Stop()
+			}
+			timer = time.NewTimer(watchDebounce)
+			fire = timer.C
+
+		case <-fire:
+			fire = nil
+			for path := range pending {
+				if err := ValidateResponseFile(path); err != nil {
+					fw.log.Error("Response file is invalid", map[string]any{"file": path, "error": err.Error()})
+				} else {
+					fw.log.Info("Response file changed", map[string]any{"file": path})
+				}
+				if fw.onChange != nil {
+					fw.onChange(path)
+				}
+			}
+			pending = make(map[string]bool)
+
+		case err, ok := <-fw.watcher.Errors:
+			if !ok {
+				return
+			}
+			fw.log.Error("Error watching response files", map[string]any{"error": err.Error()})
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tkc/go-json-server/src/logger"
+)
+
+// testLogger returns a logger that only reports errors
+func testLogger(t *testing.T) *logger.Logger {
+	log, err := logger.NewLogger(logger.LogConfig{Level: logger.LevelError})
+	assert.NoError(t, err)
+	return log
+}
+
+// watchConfigJSON returns a minimal config serving jsonFile on the given port
+func watchConfigJSON(port, jsonFile string) string {
+	return `{"port": ` + port + `, "endpoints": [{"method": "GET", "status": 200, "path": "/test", "jsonPath": "` + jsonFile + `"}]}`
+}
+
+// waitReload returns the next reload result or fails after a timeout
+func waitReload(t *testing.T, reloadCh <-chan bool) bool {
+	t.Helper()
+	select {
+	case ok := <-reloadCh:
+		return ok
+	case <-time.After(2 * time.Second):
+		t.Fatal("no reload was reported")
+		return false
+	}
+}
+
+func TestWatchConfig(t *testing.T) {
+	// This test is simplified as full testing would require more complex setup
+	tempDir, err := os.MkdirTemp("", "watch-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	// Create a test JSON file
+	jsonFile := filepath.Join(tempDir, "test.json")
+	err = os.WriteFile(jsonFile, []byte(`{"message":"test"}`), 0644)
+	assert.NoError(t, err)
+
+	// Create a config file
+	configPath := filepath.Join(tempDir, "config.json")
+	configContent := `{
+		"port": 8080,
+		"endpoints": [
+			{
+				"method": "GET",
+				"status": 200,
+				"path": "/test",
+				"jsonPath": "` + jsonFile + `"
+			}
+		]
+	}`
+	err = os.WriteFile(configPath, []byte(configContent), 0644)
+	assert.NoError(t, err)
+
+	// Load the config
+	cfg, err := LoadConfig(configPath)
+	assert.NoError(t, err)
+
+	// Setup a channel to receive notifications
+	reloadCh := make(chan bool, 1)
+
+	// Start watching
+	err = WatchConfig(configPath, cfg, reloadCh, testLogger(t))
+	assert.NoError(t, err)
+
+	// A broken config is reported as a failed reload and leaves the old one in place
+	time.Sleep(100 * time.Millisecond)
+	err = os.WriteFile(configPath, []byte(`{"endpoints": [`), 0644)
+	assert.NoError(t, err)
+
+	select {
+	case ok := <-reloadCh:
+		assert.False(t, ok)
+	case <-time.After(2 * time.Second):
+		t.Fatal("reload failure was not reported")
+	}
+	assert.Equal(t, 8080, cfg.Port)
+}
+
+func TestWatchConfig_RenameReplace(t *testing.T) {
+	tempDir := t.TempDir()
+	jsonFile := filepath.Join(tempDir, "test.json")
+	assert.NoError(t, os.WriteFile(jsonFile, []byte(`{"message":"test"}`), 0644))
+	configPath := filepath.Join(tempDir, "config.json")
+	assert.NoError(t, os.WriteFile(configPath, []byte(watchConfigJSON("8080", jsonFile)), 0644))
+
+	cfg, err := LoadConfig(configPath)
+	assert.NoError(t, err)
+
+	// Watch through a relative path
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(tempDir))
+	defer os.Chdir(wd)
+
+	reloadCh := make(chan bool, 4)
+	assert.NoError(t, WatchConfig("config.json", cfg, reloadCh, testLogger(t)))
+
+	// Save the way vim and JetBrains IDEs do: write a new file and rename it over the old one
+	tmp := filepath.Join(tempDir, ".config.json.swp")
+	assert.NoError(t, os.WriteFile(tmp, []byte(watchConfigJSON("9090", jsonFile)), 0644))
+	assert.NoError(t, os.Rename(tmp, configPath))
+
+	assert.True(t, waitReload(t, reloadCh))
+	assert.Equal(t, 9090, cfg.GetPort())
+
+	// Remove then recreate triggers a single reload once the file is back
+	assert.NoError(t, os.Remove(configPath))
+	assert.NoError(t, os.WriteFile(configPath, []byte(watchConfigJSON("9191", jsonFile)), 0644))
+	assert.True(t, waitReload(t, reloadCh))
+	assert.Equal(t, 9191, cfg.GetPort())
+}
+
+func TestWatchConfig_SymlinkSwap(t *testing.T) {
+	tempDir := t.TempDir()
+	jsonFile := filepath.Join(tempDir, "test.json")
+	assert.NoError(t, os.WriteFile(jsonFile, []byte(`{"message":"test"}`), 0644))
+
+	// Lay out files like a Kubernetes ConfigMap volume:
+	// config.json -> ..data/config.json, ..data -> ..v1
+	writeVersion := func(version, port string) {
+		dir := filepath.Join(tempDir, version)
+		assert.NoError(t, os.MkdirAll(dir, 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "config.json"), []byte(watchConfigJSON(port, jsonFile)), 0644))
+	}
+	writeVersion("..v1", "8080")
+	assert.NoError(t, os.Symlink("..v1", filepath.Join(tempDir, "..data")))
+	configPath := filepath.Join(tempDir, "config.json")
+	assert.NoError(t, os.Symlink(filepath.Join("..data", "config.json"), configPath))
+
+	cfg, err := LoadConfig(configPath)
+	assert.NoError(t, err)
+
+	reloadCh := make(chan bool, 4)
+	assert.NoError(t, WatchConfig(configPath, cfg, reloadCh, testLogger(t)))
+
+	// Atomically swap the data directory symlink
+	writeVersion("..v2", "9090")
+	assert.NoError(t, os.Symlink("..v2", filepath.Join(tempDir, "..data_tmp")))
+	assert.NoError(t, os.Rename(filepath.Join(tempDir, "..data_tmp"), filepath.Join(tempDir, "..data")))
+	assert.NoError(t, os.RemoveAll(filepath.Join(tempDir, "..v1")))
+
+	assert.True(t, waitReload(t, reloadCh))
+	assert.Equal(t, 9090, cfg.GetPort())
+
+	// Writes to the new target are followed
+	assert.NoError(t, os.WriteFile(filepath.Join(tempDir, "..v2", "config.json"), []byte(watchConfigJSON("9191", jsonFile)), 0644))
+	assert.True(t, waitReload(t, reloadCh))
+	assert.Equal(t, 9191, cfg.GetPort())
+}
+
+func TestWatchConfig_Debounce(t *testing.T) {
+	tempDir := t.TempDir()
+	jsonFile := filepath.Join(tempDir, "test.json")
+	assert.NoError(t, os.WriteFile(jsonFile, []byte(`{"message":"test"}`), 0644))
+	configPath := filepath.Join(tempDir, "config.json")
+	assert.NoError(t, os.WriteFile(configPath, []byte(watchConfigJSON("8080", jsonFile)), 0644))
+
+	cfg, err := LoadConfig(configPath)
+	assert.NoError(t, err)
+
+	reloadCh := make(chan bool, 4)
+	assert.NoError(t, WatchConfig(configPath, cfg, reloadCh, testLogger(t)))
+
+	// A burst of writes, including a truncated intermediate state, is reloaded once
+	assert.NoError(t, os.WriteFile(configPath, []byte(`{"endpoints": [`), 0644))
+	assert.NoError(t, os.Chmod(configPath, 0600))
+	assert.NoError(t, os.WriteFile(configPath, []byte(watchConfigJSON("9090", jsonFile)), 0644))
+
+	assert.True(t, waitReload(t, reloadCh))
+	assert.Equal(t, 9090, cfg.GetPort())
+
+	select {
+	case <-reloadCh:
+		t.Fatal("burst of events triggered more than one reload")
+	case <-time.After(3 * watchDebounce):
+	}
+
+	// Events for other files in the directory are ignored
+	assert.NoError(t, os.WriteFile(filepath.Join(tempDir, "other.json"), []byte(`{}`), 0644))
+	select {
+	case <-reloadCh:
+		t.Fatal("unrelated file triggered a reload")
+	case <-time.After(3 * watchDebounce):
+	}
+}
+
+func TestWatchResponseFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	usersFile := filepath.Join(tempDir, "users.json")
+	emptyFile := filepath.Join(tempDir, "variants", "empty.json")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(emptyFile), 0755))
+	assert.NoError(t, os.WriteFile(usersFile, []byte(`[{"id": 1}]`), 0644))
+	assert.NoError(t, os.WriteFile(emptyFile, []byte(`[]`), 0644))
+
+	cfg := &Config{Endpoints: []Endpoint{{
+		Method:   "GET",
+		Status:   200,
+		Path:     "/users",
+		JsonPath: usersFile,
+		Variants: map[string]ResponseVariant{"empty": {JsonPath: emptyFile}},
+	}}}
+	assert.Equal(t, []string{usersFile, emptyFile}, cfg.ResponseFiles())
+
+	changed := make(chan string, 8)
+	fw, err := WatchResponseFiles(cfg, testLogger(t), func(path string) { changed <- path })
+	assert.NoError(t, err)
+	defer fw.Close()
+
+	waitChange := func() string {
+		t.Helper()
+		select {
+		case path := <-changed:
+			return path
+		case <-time.After(2 * time.Second):
+			t.Fatal("no change was reported")
+			return ""
+		}
+	}
+
+	// In-place writes are reported once per burst
+	assert.NoError(t, os.WriteFile(usersFile, []byte(`[{"id": 1}, `), 0644))
+	assert.NoError(t, os.WriteFile(usersFile, []byte(`[{"id": 1}, {"id": 2}]`), 0644))
+	assert.Equal(t, usersFile, waitChange())
+
+	// Rename-replace saves of variant files are reported
+	tmp := filepath.Join(tempDir, "variants", ".empty.json.tmp")
+	assert.NoError(t, os.WriteFile(tmp, []byte(`[{"id": 3}]`), 0644))
+	assert.NoError(t, os.Rename(tmp, emptyFile))
+	assert.Equal(t, emptyFile, waitChange())
+
+	// Unreferenced files are ignored
+	assert.NoError(t, os.WriteFile(filepath.Join(tempDir, "other.json"), []byte(`{}`), 0644))
+	select {
+	case path := <-changed:
+		t.Fatalf("unexpected change of %s", path)
+	case <-time.After(3 * watchDebounce):
+	}
+
+	// Files referenced after a change to the endpoints are watched once refreshed
+	postsFile := filepath.Join(tempDir, "posts", "posts.json")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(postsFile), 0755))
+	assert.NoError(t, os.WriteFile(postsFile, []byte(`[]`), 0644))
+	_, err = cfg.AddEndpoint(Endpoint{Method: "GET", Status: 200, Path: "/posts", JsonPath: postsFile})
+	assert.NoError(t, err)
+	assert.NoError(t, fw.Refresh())
+
+	assert.NoError(t, os.WriteFile(postsFile, []byte(`[{"id": 1}]`), 0644))
+	assert.Equal(t, postsFile, waitChange())
+}
+
+func TestValidateResponseFile(t *testing.T) {
+	tempDir := t.TempDir()
+
+	valid := filepath.Join(tempDir, "valid.json")
+	assert.NoError(t, os.WriteFile(valid, []byte(`{"ok": true}`), 0644))
+	assert.NoError(t, ValidateResponseFile(valid))
+
+	broken := filepath.Join(tempDir, "broken.json")
+	assert.NoError(t, os.WriteFile(broken, []byte(`{"ok": `), 0644))
+	assert.ErrorIs(t, ValidateResponseFile(broken), ErrInvalidResponse)
+
+	// Only .json files have to hold JSON
+	text := filepath.Join(tempDir, "hello.txt")
+	assert.NoError(t, os.WriteFile(text, []byte(`hello`), 0644))
+	assert.NoError(t, ValidateResponseFile(text))
+
+	assert.ErrorIs(t, ValidateResponseFile(filepath.Join(tempDir, "missing.json")), ErrJSONFileNotFound)
+}
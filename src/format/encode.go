@@ -0,0 +1,169 @@
+package format
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// XML element names used where JSON has none
+const (
+	xmlRoot = "response"
+	xmlItem = "item"
+)
+
+// encodeXML renders data under a <response> root. Object keys become
+// elements and array values <item> elements.
+func encodeXML(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := writeXML(enc, xmlRoot, v); err != nil {
+		return nil, err
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// writeXML writes v as the element name
+func writeXML(enc *xml.Encoder, name string, v any) error {
+	start := xml.StartElement{Name: xml.Name{Local: xmlName(name)}}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+
+	switch v := v.(type) {
+	case object:
+		for _, m := range v {
+			if err := writeXML(enc, m.key, m.value); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if err := writeXML(enc, xmlItem, item); err != nil {
+				return err
+			}
+		}
+	default:
+		text, _ := scalarString(v)
+		if err := enc.EncodeToken(xml.CharData(text)); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// xmlName turns a JSON key into a valid XML element name
+func xmlName(key string) string {
+	var b strings.Builder
+	for i, r := range key {
+		valid := unicode.IsLetter(r) || r == '_' || (i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.'))
+		if !valid {
+			if i == 0 && unicode.IsDigit(r) {
+				b.WriteRune('_')
+				b.WriteRune(r)
+				continue
+			}
+			r = '_'
+		}
+		b.WriteRune(r)
+	}
+	if b.Len() == 0 {
+		return "_"
+	}
+	return b.String()
+}
+
+// encodeYAML renders data as a YAML document, keeping the key order
+func encodeYAML(v any) ([]byte, error) {
+	return yaml.Marshal(yamlNode(v))
+}
+
+// yamlNode converts a decoded value to a YAML node
+func yamlNode(v any) *yaml.Node {
+	switch v := v.(type) {
+	case object:
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, m := range v {
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: m.key}, yamlNode(m.value))
+		}
+		return node
+	case []any:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range v {
+			node.Content = append(node.Content, yamlNode(item))
+		}
+		return node
+	case nil:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprint(v)}
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(v.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: v.String()}
+	default:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: fmt.Sprint(v)}
+	}
+}
+
+// encodeCSV renders an array of flat objects, or a single flat object, as
+// CSV with a header row of all keys in the order they first appear
+func encodeCSV(v any) ([]byte, error) {
+	var rows []object
+	switch v := v.(type) {
+	case object:
+		rows = []object{v}
+	case []any:
+		for _, item := range v {
+			row, ok := item.(object)
+			if !ok {
+				return nil, fmt.Errorf("%w: CSV needs an array of objects", ErrNotRepresentable)
+			}
+			rows = append(rows, row)
+		}
+	default:
+		return nil, fmt.Errorf("%w: CSV needs an array of objects", ErrNotRepresentable)
+	}
+
+	var header []string
+	columns := make(map[string]int)
+	for _, row := range rows {
+		for _, m := range row {
+			if _, ok := columns[m.key]; !ok {
+				columns[m.key] = len(header)
+				header = append(header, m.key)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(header)
+	for _, row := range rows {
+		record := make([]string, len(header))
+		for _, m := range row {
+			text, ok := scalarString(m.value)
+			if !ok {
+				return nil, fmt.Errorf("%w: CSV field %s is not a scalar", ErrNotRepresentable, m.key)
+			}
+			record[columns[m.key]] = text
+		}
+		w.Write(record)
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
@@ -0,0 +1,239 @@
+// Package format converts JSON response data to the other representations a
+// client can ask for, and negotiates the representation from the Accept header.
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrNotRepresentable is returned when data has no representation in a format,
+// such as nested objects in CSV
+var ErrNotRepresentable = errors.New("data cannot be represented in this format")
+
+// Format is a representation of JSON data
+type Format struct {
+	// Name selects the format with the _format query parameter
+	Name        string
+	ContentType string
+	// MediaTypes are matched against the Accept header
+	MediaTypes []string
+	// Suffix matches media types with a structured syntax suffix, such as "+json"
+	Suffix string
+
+	encode func(v any) ([]byte, error)
+}
+
+// Encode converts JSON data to the format
+func (f *Format) Encode(data []byte) ([]byte, error) {
+	if f.encode == nil {
+		return data, nil
+	}
+	v, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	return f.encode(v)
+}
+
+// JSON is the format of the response files; its Encode returns the data unchanged
+var JSON = &Format{
+	Name:        "json",
+	ContentType: "application/json; charset=UTF-8",
+	// Browsers navigating to an endpoint ask for HTML and are shown JSON
+	MediaTypes: []string{"application/json", "text/html"},
+	// JSON based types such as application/problem+json are served as JSON
+	Suffix: "+json",
+}
+
+// Formats are the supported formats, JSON first
+var Formats = []*Format{
+	JSON,
+	{Name: "xml", ContentType: "application/xml; charset=UTF-8", MediaTypes: []string{"application/xml", "text/xml"}, encode: encodeXML},
+	{Name: "yaml", ContentType: "application/yaml; charset=UTF-8", MediaTypes: []string{"application/yaml", "application/x-yaml", "text/yaml"}, encode: encodeYAML},
+	{Name: "csv", ContentType: "text/csv; charset=UTF-8", MediaTypes: []string{"text/csv"}, encode: encodeCSV},
+	{Name: "msgpack", ContentType: "application/msgpack", MediaTypes: []string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"}, encode: encodeMsgPack},
+}
+
+// ByName returns the format selected by a _format query parameter value
+func ByName(name string) (*Format, bool) {
+	for _, f := range Formats {
+		if strings.EqualFold(f.Name, name) {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// acceptRange is a media range of an Accept header
+type acceptRange struct {
+	mediaType string
+	quality   float64
+	// order keeps ranges of equal quality in header order
+	order int
+}
+
+// Negotiate returns the formats acceptable for an Accept header value, most
+// preferred first. An empty header accepts every format with JSON first.
+func Negotiate(accept string) []*Format {
+	if strings.TrimSpace(accept) == "" {
+		return Formats
+	}
+
+	var ranges []acceptRange
+	for i, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		ranges = append(ranges, acceptRange{mediaType: mediaType, quality: quality, order: i})
+	}
+
+	type candidate struct {
+		format *Format
+		accept acceptRange
+		// specificity ranks exact types over type/* over */*
+		specificity int
+	}
+	var candidates []candidate
+	for _, f := range Formats {
+		best, found := candidate{format: f, specificity: -1}, false
+		for _, r := range ranges {
+			specificity := f.matchRange(r.mediaType)
+			if specificity > best.specificity {
+				best.accept, best.specificity, found = r, specificity, true
+			}
+		}
+		// The most specific range decides, so "*/*, text/csv;q=0" excludes CSV
+		if found && best.accept.quality > 0 {
+			candidates = append(candidates, best)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.accept.quality != b.accept.quality {
+			return a.accept.quality > b.accept.quality
+		}
+		if a.specificity != b.specificity {
+			return a.specificity > b.specificity
+		}
+		return a.accept.order < b.accept.order
+	})
+
+	formats := make([]*Format, len(candidates))
+	for i, c := range candidates {
+		formats[i] = c.format
+	}
+	return formats
+}
+
+// matchRange returns how specifically a media range matches the format: 2 for
+// one of its types or suffix, 1 for type/*, 0 for */* and -1 for no match
+func (f *Format) matchRange(mediaRange string) int {
+	if f.Suffix != "" && strings.HasSuffix(mediaRange, f.Suffix) {
+		return 2
+	}
+	best := -1
+	for _, mediaType := range f.MediaTypes {
+		switch {
+		case mediaRange == mediaType:
+			return 2
+		case strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(mediaRange, "*")):
+			best = max(best, 1)
+		case mediaRange == "*/*":
+			best = max(best, 0)
+		}
+	}
+	return best
+}
+
+// member is a key of a JSON object with its value
+type member struct {
+	key   string
+	value any
+}
+
+// object is a JSON object that keeps the order of its keys
+type object []member
+
+// decode parses JSON into nil, bool, json.Number, string, []any and object values
+func decode(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	v, err := decodeValue(dec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("invalid JSON: trailing data")
+	}
+	return v, nil
+}
+
+// decodeValue reads the next value from dec
+func decodeValue(dec *json.Decoder) (any, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		obj := object{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, member{key: key.(string), value: value})
+		}
+		_, err := dec.Token()
+		return obj, err
+
+	case json.Delim('['):
+		arr := []any{}
+		for dec.More() {
+			value, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, value)
+		}
+		_, err := dec.Token()
+		return arr, err
+	}
+	return token, nil
+}
+
+// scalarString renders a JSON scalar as text, reporting false for objects and arrays
+func scalarString(v any) (string, bool) {
+	switch v := v.(type) {
+	case nil:
+		return "", true
+	case bool:
+		return strconv.FormatBool(v), true
+	case json.Number:
+		return v.String(), true
+	case string:
+		return v, true
+	}
+	return "", false
+}
@@ -0,0 +1,101 @@
+package format
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const users = `[
+	{"id": 1, "name": "Alice", "active": true},
+	{"id": 2, "name": "Bob, Jr.", "email": null}
+]`
+
+// names returns the names of formats
+func names(formats []*Format) []string {
+	var result []string
+	for _, f := range formats {
+		result = append(result, f.Name)
+	}
+	return result
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   []string
+	}{
+		{"", []string{"json", "xml", "yaml", "csv", "msgpack"}},
+		{"*/*", []string{"json", "xml", "yaml", "csv", "msgpack"}},
+		{"text/csv", []string{"csv"}},
+		{"application/xml;q=0.5, application/yaml", []string{"yaml", "xml"}},
+		{"text/*", []string{"json", "xml", "yaml", "csv"}},
+		{"*/*;q=0.1, text/csv;q=0, application/x-msgpack", []string{"msgpack", "json", "xml", "yaml"}},
+		// Browser navigations get JSON rather than the XML they also accept
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", []string{"json", "xml", "yaml", "csv", "msgpack"}},
+		{"image/png", nil},
+		// Structured syntax suffixes are JSON
+		{"application/vnd.api+json", []string{"json"}},
+		{"application/problem+json, application/xml;q=0.5", []string{"json", "xml"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			assert.Equal(t, tt.want, names(Negotiate(tt.accept)))
+		})
+	}
+}
+
+func TestFormat_Encode(t *testing.T) {
+	xml, _ := ByName("xml")
+	yaml, _ := ByName("yaml")
+	csv, _ := ByName("csv")
+	msgpack, _ := ByName("msgpack")
+
+	out, err := JSON.Encode([]byte(users))
+	assert.NoError(t, err)
+	assert.Equal(t, users, string(out))
+
+	out, err = xml.Encode([]byte(`{"user": {"id": 7, "first name": "A&B", "tags": ["x", "y"], "2fa": false}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<response>
+  <user>
+    <id>7</id>
+    <first_name>A&amp;B</first_name>
+    <tags>
+      <item>x</item>
+      <item>y</item>
+    </tags>
+    <_2fa>false</_2fa>
+  </user>
+</response>
+`, string(out))
+
+	out, err = yaml.Encode([]byte(users))
+	assert.NoError(t, err)
+	assert.Equal(t, `- id: 1
+  name: Alice
+  active: true
+- id: 2
+  name: Bob, Jr.
+  email: null
+`, string(out))
+
+	out, err = csv.Encode([]byte(users))
+	assert.NoError(t, err)
+	assert.Equal(t, "id,name,active,email\n1,Alice,true,\n2,\"Bob, Jr.\",,\n", string(out))
+
+	_, err = csv.Encode([]byte(`[{"id": 1, "address": {"city": "Tokyo"}}]`))
+	assert.ErrorIs(t, err, ErrNotRepresentable)
+	_, err = csv.Encode([]byte(`"text"`))
+	assert.ErrorIs(t, err, ErrNotRepresentable)
+
+	out, err = msgpack.Encode([]byte(`{"a": [1, -1, 200, -200, 70000, 1.5, null, true, "hi"]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "81"+"a161"+"99"+"01"+"ff"+"ccc8"+"d1ff38"+"ce00011170"+"cb3ff8000000000000"+"c0"+"c3"+"a26869",
+		hex.EncodeToString(out))
+
+	_, err = xml.Encode([]byte(`{"broken"`))
+	assert.Error(t, err)
+}
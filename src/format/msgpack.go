@@ -0,0 +1,121 @@
+package format
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"strconv"
+)
+
+// encodeMsgPack renders data in MessagePack. Integers use the smallest
+// encoding that holds them and other numbers become 64-bit floats.
+func encodeMsgPack(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeMsgPack(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeMsgPack appends the encoding of v to buf
+func writeMsgPack(buf *bytes.Buffer, v any) error {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if v {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case json.Number:
+		return writeMsgPackNumber(buf, v)
+	case string:
+		writeMsgPackHeader(buf, len(v), 0xa0, 31, 0xd9, 0xda, 0xdb)
+		buf.WriteString(v)
+	case []any:
+		writeMsgPackHeader(buf, len(v), 0x90, 15, 0, 0xdc, 0xdd)
+		for _, item := range v {
+			if err := writeMsgPack(buf, item); err != nil {
+				return err
+			}
+		}
+	case object:
+		writeMsgPackHeader(buf, len(v), 0x80, 15, 0, 0xde, 0xdf)
+		for _, m := range v {
+			writeMsgPackHeader(buf, len(m.key), 0xa0, 31, 0xd9, 0xda, 0xdb)
+			buf.WriteString(m.key)
+			if err := writeMsgPack(buf, m.value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeMsgPackHeader writes the type and length of a string, array or map:
+// the fix type when the length fits in fixMax, else the 8, 16 or 32-bit
+// length type. A zero code8 means there is no 8-bit length type.
+func writeMsgPackHeader(buf *bytes.Buffer, n int, fix byte, fixMax int, code8, code16, code32 byte) {
+	switch {
+	case n <= fixMax:
+		buf.WriteByte(fix | byte(n))
+	case code8 != 0 && n <= math.MaxUint8:
+		buf.Write([]byte{code8, byte(n)})
+	case n <= math.MaxUint16:
+		buf.WriteByte(code16)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	default:
+		buf.WriteByte(code32)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	}
+}
+
+// writeMsgPackNumber writes a JSON number as an integer when it is one
+func writeMsgPackNumber(buf *bytes.Buffer, n json.Number) error {
+	if i, err := strconv.ParseInt(n.String(), 10, 64); err == nil {
+		switch {
+		case i >= 0 && i <= 127:
+			buf.WriteByte(byte(i))
+		case i < 0 && i >= -32:
+			buf.WriteByte(byte(int8(i)))
+		case i >= 0 && i <= math.MaxUint8:
+			buf.Write([]byte{0xcc, byte(i)})
+		case i >= 0 && i <= math.MaxUint16:
+			buf.WriteByte(0xcd)
+			buf.Write(binary.BigEndian.AppendUint16(nil, uint16(i)))
+		case i >= 0 && i <= math.MaxUint32:
+			buf.WriteByte(0xce)
+			buf.Write(binary.BigEndian.AppendUint32(nil, uint32(i)))
+		case i >= 0:
+			buf.WriteByte(0xcf)
+			buf.Write(binary.BigEndian.AppendUint64(nil, uint64(i)))
+		case i >= math.MinInt8:
+			buf.Write([]byte{0xd0, byte(int8(i))})
+		case i >= math.MinInt16:
+			buf.WriteByte(0xd1)
+			buf.Write(binary.BigEndian.AppendUint16(nil, uint16(int16(i))))
+		case i >= math.MinInt32:
+			buf.WriteByte(0xd2)
+			buf.Write(binary.BigEndian.AppendUint32(nil, uint32(int32(i))))
+		default:
+			buf.WriteByte(0xd3)
+			buf.Write(binary.BigEndian.AppendUint64(nil, uint64(i)))
+		}
+		return nil
+	}
+	if u, err := strconv.ParseUint(n.String(), 10, 64); err == nil {
+		buf.WriteByte(0xcf)
+		buf.Write(binary.BigEndian.AppendUint64(nil, u))
+		return nil
+	}
+
+	f, err := n.Float64()
+	if err != nil {
+		return err
+	}
+	buf.WriteByte(0xcb)
+	buf.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(f)))
+	return nil
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/tkc/go-json-server/src/config"
+	"github.com/tkc/go-json-server/src/format"
+)
+
+// FormatParam is the query parameter that selects the response format,
+// overriding the Accept header
+const FormatParam = "_format"
+
+// extensionTypes are the content types of common response files whose
+// registration varies between systems
+var extensionTypes = map[string]string{
+	".json": MIMEApplicationJSONUTF8,
+	".txt":  MIMETextPlainUTF8,
+	".csv":  "text/csv; charset=UTF-8",
+	".xml":  "application/xml; charset=UTF-8",
+	".html": "text/html; charset=UTF-8",
+	".yaml": "application/yaml; charset=UTF-8",
+	".yml":  "application/yaml; charset=UTF-8",
+}
+
+// responseContentType returns the Content-Type of an endpoint's response:
+// the type set in the config, else the type of the response file's
+// extension, else the type sniffed from binary content. JSON is the default.
+func responseContentType(ep config.Endpoint, file string, content []byte) string {
+	if contentType := ep.MediaType(); contentType != "" {
+		return contentType
+	}
+
+	if ext := strings.ToLower(filepath.Ext(file)); ext != "" {
+		if contentType, ok := extensionTypes[ext]; ok {
+			return contentType
+		}
+		if contentType := mime.TypeByExtension(ext); contentType != "" {
+			return contentType
+		}
+		return http.DetectContentType(content)
+	}
+	if file == "" && ep.BodyBase64 != "" {
+		return http.DetectContentType(content)
+	}
+	return MIMEApplicationJSONUTF8
+}
+
+// isTextContentType reports whether a content type holds text, in which path
+// parameters are substituted
+func isTextContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	if strings.HasPrefix(mediaType, "text/") || strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml") {
+		return true
+	}
+	switch mediaType {
+	case MIMEApplicationJSON, "application/xml", "application/yaml", "application/javascript":
+		return true
+	}
+	return false
+}
+
+// isJSONContentType reports whether a content type holds JSON
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == MIMEApplicationJSON || strings.HasSuffix(mediaType, "+json"))
+}
+
+// contentDisposition returns the Content-Disposition offering a download
+// under the given file name
+func contentDisposition(filename string) string {
+	return mime.FormatMediaType("attachment", map[string]string{"filename": filename})
+}
+
+// negotiateFormat converts a JSON body to the first format acceptable to the
+// client that can represent it, such as CSV for arrays of flat objects. An
+// Accept header naming no supported format gets JSON.
+func negotiateFormat(r *http.Request, body []byte) (*format.Format, []byte, error) {
+	var candidates []*format.Format
+	if name := r.URL.Query().Get(FormatParam); name != "" {
+		if f, ok := format.ByName(name); ok {
+			candidates = []*format.Format{f}
+		}
+	} else {
+		candidates = format.Negotiate(r.Header.Get("Accept"))
+		if len(candidates) == 0 {
+			return format.JSON, body, nil
+		}
+	}
+
+	for _, f := range candidates {
+		// Formats that cannot represent the body are skipped
+		if converted, err := f.Encode(body); err == nil {
+			return f, converted, nil
+		}
+	}
+	return nil, nil, ErrNotAcceptable
+}
@@ -0,0 +1,277 @@
+package handler
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tkc/go-json-server/src/config"
+	"github.com/tkc/go-json-server/src/metrics"
+)
+
+// DefaultCacheSweepInterval is how often expired responses are removed
+const DefaultCacheSweepInterval = 30 * time.Second
+
+// ResponseCache is a least recently used cache of rendered responses, bounded
+// by the number of entries and their total size. Expired entries are removed
+// by a background sweep that runs while the cache holds entries.
+type ResponseCache struct {
+	// SweepInterval is how often expired entries are removed
+	SweepInterval time.Duration
+
+	mu         sync.Mutex
+	entries    map[string]*list.Element
+	lru        *list.List
+	bytes      int64
+	maxEntries int
+	maxBytes   int64
+	sweeping   bool
+
+	hits        uint64
+	misses      uint64
+	evictions   uint64
+	expirations uint64
+}
+
+// cachedResponse represents a cached response
+type cachedResponse struct {
+	key        string
+	content    []byte
+	file       string
+	expiration time.Time
+}
+
+// size is what an entry counts against the byte bound
+func (e *cachedResponse) size() int64 {
+	return int64(len(e.key) + len(e.content))
+}
+
+// CacheStats reports the state of the response cache
+type CacheStats struct {
+	Entries     int    `json:"entries"`
+	Bytes       int64  `json:"bytes"`
+	MaxEntries  int    `json:"maxEntries"`
+	MaxBytes    int64  `json:"maxBytes"`
+	Hits        uint64 `json:"hits"`
+	Misses      uint64 `json:"misses"`
+	Evictions   uint64 `json:"evictions"`
+	Expirations uint64 `json:"expirations"`
+}
+
+// NewResponseCache creates a response cache holding at most maxEntries
+// responses of maxBytes in total
+func NewResponseCache(maxEntries int, maxBytes int64) *ResponseCache {
+	return &ResponseCache{
+		SweepInterval: DefaultCacheSweepInterval,
+		entries:       make(map[string]*list.Element),
+		lru:           list.New(),
+		maxEntries:    maxEntries,
+		maxBytes:      maxBytes,
+	}
+}
+
+// Get retrieves a cached response, marking it as recently used
+func (c *ResponseCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+
+	cached := el.Value.(*cachedResponse)
+	if time.Now().After(cached.expiration) {
+		c.remove(el)
+		c.expirations++
+		c.misses++
+		return nil, false
+	}
+
+	c.lru.MoveToFront(el)
+	c.hits++
+	return cached.content, true
+}
+
+// Set stores a response rendered from file in the cache, evicting the least
+// recently used responses to stay within the bounds. Responses larger than
+// the byte bound are not cached.
+func (c *ResponseCache) Set(key, file string, content []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		c.remove(el)
+	}
+
+	cached := &cachedResponse{
+		key:        key,
+		content:    content,
+		file:       file,
+		expiration: time.Now().Add(ttl),
+	}
+	if ttl <= 0 || cached.size() > c.maxBytes {
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(cached)
+	c.bytes += cached.size()
+	c.evict()
+
+	if !c.sweeping {
+		c.sweeping = true
+		go c.sweep(c.SweepInterval)
+	}
+}
+
+// SetLimits changes the bounds of the cache, evicting responses that no longer fit
+func (c *ResponseCache) SetLimits(maxEntries int, maxBytes int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.maxEntries, c.maxBytes = maxEntries, maxBytes
+	c.evict()
+}
+
+// InvalidateFile removes the responses rendered from file and returns how many were removed
+func (c *ResponseCache) InvalidateFile(file string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for el := c.lru.Front(); el != nil; {
+		next := el.Next()
+		if el.Value.(*cachedResponse).file == file {
+			c.remove(el)
+			removed++
+		}
+		el = next
+	}
+	return removed
+}
+
+// Clear removes all responses; the statistics are kept
+func (c *ResponseCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]*list.Element)
+	c.lru.Init()
+	c.bytes = 0
+}
+
+// Stats returns the current cache statistics
+func (c *ResponseCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return CacheStats{
+		Entries:     c.lru.Len(),
+		Bytes:       c.bytes,
+		MaxEntries:  c.maxEntries,
+		MaxBytes:    c.maxBytes,
+		Hits:        c.hits,
+		Misses:      c.misses,
+		Evictions:   c.evictions,
+		Expirations: c.expirations,
+	}
+}
+
+// RegisterMetrics exposes the cache size, evictions and expirations in a registry
+func (c *ResponseCache) RegisterMetrics(r *metrics.Registry) {
+	r.NewGaugeFunc(
+		metrics.Namespace+"_cache_entries",
+		"Number of responses in the response cache.",
+		func() float64 { return float64(c.Stats().Entries) },
+	)
+	r.NewGaugeFunc(
+		metrics.Namespace+"_cache_bytes",
+		"Total size of the responses in the response cache.",
+		func() float64 { return float64(c.Stats().Bytes) },
+	)
+	r.NewCounterFunc(
+		metrics.Namespace+"_cache_evictions_total",
+		"Total number of responses evicted to stay within the cache bounds.",
+		func() float64 { return float64(c.Stats().Evictions) },
+	)
+	r.NewCounterFunc(
+		metrics.Namespace+"_cache_expirations_total",
+		"Total number of expired responses removed from the cache.",
+		func() float64 { return float64(c.Stats().Expirations) },
+	)
+}
+
+// evict removes the least recently used responses until the cache is within its bounds
+func (c *ResponseCache) evict() {
+	for c.lru.Len() > 0 && (c.lru.Len() > c.maxEntries || c.bytes > c.maxBytes) {
+		c.remove(c.lru.Back())
+		c.evictions++
+	}
+}
+
+// remove drops an entry
+func (c *ResponseCache) remove(el *list.Element) {
+	cached := c.lru.Remove(el).(*cachedResponse)
+	delete(c.entries, cached.key)
+	c.bytes -= cached.size()
+}
+
+// sweep periodically removes expired responses and stops once the cache is empty
+func (c *ResponseCache) sweep(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		c.mu.Lock()
+		now := time.Now()
+		for el := c.lru.Front(); el != nil; {
+			next := el.Next()
+			if now.After(el.Value.(*cachedResponse).expiration) {
+				c.remove(el)
+				c.expirations++
+			}
+			el = next
+		}
+		if c.lru.Len() == 0 {
+			c.sweeping = false
+			c.mu.Unlock()
+			return
+		}
+		c.mu.Unlock()
+	}
+}
+
+// cacheKey identifies the response to a request under a cache policy: the
+// method, path and query, the selected headers and, when configured, a hash
+// of the request body. The request body is restored after hashing.
+func cacheKey(r *http.Request, policy config.CacheConfig) string {
+	var b strings.Builder
+	b.WriteString(r.Method)
+	b.WriteString(":")
+	b.WriteString(r.URL.Path)
+	if !policy.IgnoreQuery && r.URL.RawQuery != "" {
+		// Encoding sorts the parameters, so their order does not matter
+		b.WriteString("?")
+		b.WriteString(r.URL.Query().Encode())
+	}
+	for _, name := range policy.KeyHeaders {
+		b.WriteString("\n")
+		b.WriteString(http.CanonicalHeaderKey(name))
+		b.WriteString(": ")
+		b.WriteString(strings.Join(r.Header.Values(name), ", "))
+	}
+	if policy.KeyBody && r.Body != nil && r.Body != http.NoBody {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(strings.NewReader(string(body)))
+		sum := sha256.Sum256(body)
+		b.WriteString("\nbody: ")
+		b.WriteString(hex.EncodeToString(sum[:]))
+	}
+	return b.String()
+}
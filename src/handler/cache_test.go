@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tkc/go-json-server/src/config"
+)
+
+func TestResponseCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewResponseCache(2, 1<<20)
+	c.Set("a", "", []byte("A"), time.Minute)
+	c.Set("b", "", []byte("B"), time.Minute)
+
+	// Reading a makes b the least recently used
+	_, ok := c.Get("a")
+	assert.True(t, ok)
+	c.Set("c", "", []byte("C"), time.Minute)
+
+	_, ok = c.Get("b")
+	assert.False(t, ok)
+	_, ok = c.Get("a")
+	assert.True(t, ok)
+	_, ok = c.Get("c")
+	assert.True(t, ok)
+
+	stats := c.Stats()
+	assert.Equal(t, 2, stats.Entries)
+	assert.Equal(t, uint64(1), stats.Evictions)
+	assert.Equal(t, uint64(3), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Misses)
+}
+
+func TestResponseCache_ByteBound(t *testing.T) {
+	c := NewResponseCache(100, 10)
+	c.Set("a", "", []byte("1234"), time.Minute)
+	c.Set("b", "", []byte("1234"), time.Minute)
+	assert.Equal(t, int64(10), c.Stats().Bytes)
+
+	// A third entry pushes out the oldest
+	c.Set("c", "", []byte("1234"), time.Minute)
+	stats := c.Stats()
+	assert.Equal(t, 2, stats.Entries)
+	assert.Equal(t, int64(10), stats.Bytes)
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+
+	// Responses larger than the bound are never cached
+	c.Set("d", "", []byte(strings.Repeat("x", 20)), time.Minute)
+	_, ok = c.Get("d")
+	assert.False(t, ok)
+	assert.Equal(t, 2, c.Stats().Entries)
+
+	// Shrinking the bounds evicts what no longer fits
+	c.SetLimits(1, 10)
+	assert.Equal(t, 1, c.Stats().Entries)
+}
+
+func TestResponseCache_Expiry(t *testing.T) {
+	c := NewResponseCache(10, 1<<20)
+	c.SweepInterval = 10 * time.Millisecond
+	c.Set("short", "", []byte("S"), 20*time.Millisecond)
+	c.Set("long", "", []byte("L"), time.Minute)
+
+	// The sweep removes expired entries without them being read
+	assert.Eventually(t, func() bool {
+		return c.Stats().Entries == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, uint64(1), c.Stats().Expirations)
+
+	_, ok := c.Get("long")
+	assert.True(t, ok)
+}
+
+func TestResponseCache_InvalidateFile(t *testing.T) {
+	c := NewResponseCache(10, 1<<20)
+	c.Set("a", "/data/users.json", []byte("A"), time.Minute)
+	c.Set("b", "/data/users.json", []byte("B"), time.Minute)
+	c.Set("c", "/data/posts.json", []byte("C"), time.Minute)
+
+	assert.Equal(t, 2, c.InvalidateFile("/data/users.json"))
+	assert.Equal(t, 1, c.Stats().Entries)
+	assert.Equal(t, int64(2), c.Stats().Bytes)
+
+	c.Clear()
+	assert.Equal(t, 0, c.Stats().Entries)
+	assert.Equal(t, int64(0), c.Stats().Bytes)
+}
+
+func TestResponseCache_Concurrent(t *testing.T) {
+	c := NewResponseCache(50, 1<<20)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				key := fmt.Sprintf("%d-%d", i, j%80)
+				if _, ok := c.Get(key); !ok {
+					// Some entries expire immediately, exercising removal on read
+					c.Set(key, "", []byte(key), time.Duration(j%2)*time.Minute+time.Nanosecond)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, c.Stats().Entries, 50)
+}
+
+func TestCacheKey(t *testing.T) {
+	policy := config.CacheConfig{}
+
+	// Query parameter order does not matter
+	a := cacheKey(httptest.NewRequest("GET", "/users?b=2&a=1", nil), policy)
+	b := cacheKey(httptest.NewRequest("GET", "/users?a=1&b=2", nil), policy)
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a, cacheKey(httptest.NewRequest("GET", "/users?a=2", nil), policy))
+	assert.NotEqual(t, a, cacheKey(httptest.NewRequest("POST", "/users?a=1&b=2", nil), policy))
+
+	// The query can be ignored
+	policy.IgnoreQuery = true
+	assert.Equal(t, cacheKey(httptest.NewRequest("GET", "/users", nil), policy), cacheKey(httptest.NewRequest("GET", "/users?a=1", nil), policy))
+
+	// Selected headers are part of the key
+	policy.KeyHeaders = []string{"x-tenant"}
+	req := httptest.NewRequest("GET", "/users", nil)
+	req.Header.Set("X-Tenant", "acme")
+	other := httptest.NewRequest("GET", "/users", nil)
+	other.Header.Set("X-Tenant", "globex")
+	other.Header.Set("X-Other", "ignored")
+	assert.NotEqual(t, cacheKey(req, policy), cacheKey(other, policy))
+
+	// The body is hashed into the key and left readable
+	policy.KeyBody = true
+	first := httptest.NewRequest("POST", "/users", strings.NewReader(`{"name":"alice"}`))
+	second := httptest.NewRequest("POST", "/users", strings.NewReader(`{"name":"bob"}`))
+	assert.NotEqual(t, cacheKey(first, policy), cacheKey(second, policy))
+	body, err := io.ReadAll(first.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"alice"}`, string(body))
+}
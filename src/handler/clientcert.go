@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/tkc/go-json-server/src/config"
+)
+
+// ClientCertParamPrefix prefixes the response placeholders filled from the client certificate,
+// e.g. ":clientCert.commonName"
+const ClientCertParamPrefix = "clientCert."
+
+// clientCertificate returns the verified client certificate of a request, if any
+func clientCertificate(r *http.Request) *x509.Certificate {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.PeerCertificates) == 0 {
+		return nil
+	}
+	return r.TLS.PeerCertificates[0]
+}
+
+// clientCertParams returns the template values describing a client certificate
+func clientCertParams(cert *x509.Certificate) map[string]string {
+	sum := sha256.Sum256(cert.Raw)
+
+	return map[string]string{
+		ClientCertParamPrefix + "subject":      cert.Subject.String(),
+		ClientCertParamPrefix + "commonName":   cert.Subject.CommonName,
+		ClientCertParamPrefix + "organization": strings.Join(cert.Subject.Organization, ","),
+		ClientCertParamPrefix + "issuer":       cert.Issuer.String(),
+		ClientCertParamPrefix + "serial":       cert.SerialNumber.String(),
+		ClientCertParamPrefix + "fingerprint":  hex.EncodeToString(sum[:]),
+		ClientCertParamPrefix + "sans":         strings.Join(config.CertSANs(cert), ","),
+		ClientCertParamPrefix + "dnsNames":     strings.Join(cert.DNSNames, ","),
+		ClientCertParamPrefix + "emails":       strings.Join(cert.EmailAddresses, ","),
+	}
+}
+
+// clientCertAccepted reports whether a request satisfies an endpoint's client certificate match
+func clientCertAccepted(ep config.Endpoint, r *http.Request) bool {
+	return ep.ClientCert == nil || ep.ClientCert.Matches(clientCertificate(r))
+}
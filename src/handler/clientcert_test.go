@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tkc/go-json-server/src/config"
+)
+
+// newTestCert issues a certificate for commonName, signed by parent or self-signed
+func newTestCert(t *testing.T, commonName string, parent *x509.Certificate, parentKey crypto.Signer) (*x509.Certificate, crypto.Signer) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName, Organization: []string{"Example"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), parentKey)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	return cert, key
+}
+
+func TestServer_ClientCert(t *testing.T) {
+	server := newTestServer(t, `{"user": ":clientCert.commonName", "org": ":clientCert.organization"}`,
+		config.Endpoint{Method: "GET", Status: 200, Path: "/admin", ClientCert: &config.ClientCertMatch{CommonName: "alice"}},
+		config.Endpoint{Method: "GET", Status: 200, Path: "/me"},
+	)
+
+	ca, caKey := newTestCert(t, "Test CA", nil, nil)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(server.HandleRequest))
+	ts.TLS = &tls.Config{ClientAuth: tls.VerifyClientCertIfGiven, ClientCAs: pool}
+	ts.StartTLS()
+	defer ts.Close()
+
+	// client presents a certificate for commonName
+	client := func(commonName string) *http.Client {
+		cert, key := newTestCert(t, commonName, ca, caKey)
+		transport := ts.Client().Transport.(*http.Transport).Clone()
+		transport.TLSClientConfig.Certificates = []tls.Certificate{{
+			Certificate: [][]byte{cert.Raw},
+			PrivateKey:  key,
+			Leaf:        cert,
+		}}
+		return &http.Client{Transport: transport}
+	}
+	alice, bob := client("alice"), client("bob")
+
+	get := func(c *http.Client, path string) (int, string) {
+		resp, err := c.Get(ts.URL + path)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		return resp.StatusCode, string(body)
+	}
+
+	t.Run("matching certificate", func(t *testing.T) {
+		status, body := get(alice, "/admin")
+		assert.Equal(t, http.StatusOK, status)
+		assert.JSONEq(t, `{"user": "alice", "org": "Example"}`, body)
+	})
+
+	t.Run("certificate not accepted", func(t *testing.T) {
+		status, body := get(bob, "/admin")
+		assert.Equal(t, http.StatusForbidden, status)
+		assert.JSONEq(t, `{"error": "Client certificate not accepted"}`, body)
+
+		// Without a certificate the endpoint is not accepted either
+		status, _ = get(ts.Client(), "/admin")
+		assert.Equal(t, http.StatusForbidden, status)
+	})
+
+	t.Run("responses are cached per certificate", func(t *testing.T) {
+		server.Cache.Clear()
+		hits := server.Cache.Stats().Hits
+
+		_, body := get(alice, "/me")
+		assert.JSONEq(t, `{"user": "alice", "org": "Example"}`, body)
+		_, body = get(bob, "/me")
+		assert.JSONEq(t, `{"user": "bob", "org": "Example"}`, body)
+		_, body = get(alice, "/me")
+		assert.JSONEq(t, `{"user": "alice", "org": "Example"}`, body)
+
+		// The third request is served from alice's entry
+		stats := server.Cache.Stats()
+		assert.Equal(t, 2, stats.Entries)
+		assert.Equal(t, hits+1, stats.Hits)
+	})
+}
@@ -8,13 +8,19 @@ import (
 	"io"
 	"net/http"
 	"os"
-	"regexp"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/tkc/go-json-server/src/config"
+	"github.com/tkc/go-json-server/src/format"
 	"github.com/tkc/go-json-server/src/logger"
+	"github.com/tkc/go-json-server/src/metrics"
+	"github.com/tkc/go-json-server/src/middleware"
 )
 
 // Error definitions
@@ -24,6 +30,7 @@ var (
 	ErrInvalidJSON      = errors.New("invalid JSON format")
 	ErrJSONFileNotFound = errors.New("JSON file not found")
 	ErrMethodNotAllowed = errors.New("method not allowed")
+	ErrNotAcceptable    = errors.New("no acceptable response format")
 )
 
 // Content type constants
@@ -41,245 +48,440 @@ const (
 	PathParamsKey contextKey = "pathParams"
 )
 
-// ResponseCache caches JSON responses
-type ResponseCache struct {
-	mu    sync.RWMutex
-	cache map[string]cachedResponse
+// Server represents the JSON server
+type Server struct {
+	Config        *config.Config
+	Logger        *logger.Logger
+	Cache         *ResponseCache
+	CacheTTL      time.Duration
+	TokenVerifier middleware.TokenVerifier
+	Metrics       *metrics.Metrics
+	router        atomic.Pointer[Router]
+
+	limitersMu       sync.Mutex
+	limiters         map[string]*middleware.RateLimiter
+	limitersSweeping bool
 }
 
-// cachedResponse represents a cached response
-type cachedResponse struct {
-	content    []byte
-	expiration time.Time
+// NewServer creates a new server instance
+func NewServer(cfg *config.Config, log *logger.Logger, cacheTTL time.Duration) *Server {
+	cache := cfg.GetCache()
+	s := &Server{
+		Config:   cfg,
+		Logger:   log,
+		Cache:    NewResponseCache(cache.MaxEntries, cache.MaxBytes),
+		CacheTTL: cacheTTL,
+		limiters: make(map[string]*middleware.RateLimiter),
+	}
+	s.Rebuild()
+
+	return s
 }
 
-// NewResponseCache creates a new response cache
-func NewResponseCache() *ResponseCache {
-	return &ResponseCache{
-		cache: make(map[string]cachedResponse),
+// Router returns the router of the current endpoints, rebuilding it first
+// when the endpoints changed since it was built
+func (s *Server) Router() *Router {
+	if router := s.router.Load(); router != nil && router.generation == s.Config.Generation() {
+		return router
 	}
+	return s.Rebuild()
 }
 
-// Get retrieves a cached response
-func (c *ResponseCache) Get(key string) ([]byte, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	cached, ok := c.cache[key]
-	if !ok {
-		return nil, false
+// Rebuild compiles the current endpoints into a new router and swaps it in.
+// Requests already being handled finish on the router they started with.
+func (s *Server) Rebuild() *Router {
+	endpoints, generation := s.Config.GetEndpointsWithGeneration()
+	router := NewRouter(endpoints)
+	router.generation = generation
+
+	// The cache bounds may have changed with a reload
+	cache := s.Config.GetCache()
+	s.Cache.SetLimits(cache.MaxEntries, cache.MaxBytes)
+
+	for {
+		current := s.router.Load()
+		if current != nil && current.generation >= generation {
+			// A concurrent rebuild already installed this or a newer version
+			return current
+		}
+		if s.router.CompareAndSwap(current, router) {
+			s.pruneLimiters(endpoints)
+			return router
+		}
 	}
+}
 
-	// Check if the cache has expired
-	if time.Now().After(cached.expiration) {
-		delete(c.cache, key)
-		return nil, false
-	}
+// HandleRequest handles all HTTP requests
+func (s *Server) HandleRequest(w http.ResponseWriter, r *http.Request) {
+	// Only the endpoints of the virtual host addressed by the request are candidates
+	router := s.Router()
+	groups := router.forHost(r.Host)
 
-	return cached.content, true
-}
+	// Remember endpoints that matched but were skipped for the client certificate
+	certRejected := false
 
-// Set stores a response in the cache
-func (c *ResponseCache) Set(key string, content []byte, ttl time.Duration) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	// Check for file server endpoints first
+	folder := router.matchFolder(groups, r.URL.Path, func(rt *route) bool {
+		if !clientCertAccepted(rt.endpoint, r) {
+			certRejected = true
+			return false
+		}
+		return true
+	})
+	if folder != nil {
+		// This is a static file server endpoint
+		ep := folder.endpoint
+		middleware.SetRoute(r, ep.ID, ep.Path)
+		fileServer := http.StripPrefix(ep.Path, http.FileServer(http.Dir(ep.Folder)))
+		s.protect(ep, fileServer).ServeHTTP(w, r)
+		return
+	}
 
-	c.cache[key] = cachedResponse{
-		content:    content,
-		expiration: time.Now().Add(ttl),
+	// Handle API endpoints, the most specific path first
+	accept := func(method string) func(*route) bool {
+		return func(rt *route) bool {
+			if rt.endpoint.Method != method {
+				return false
+			}
+			if !clientCertAccepted(rt.endpoint, r) {
+				certRejected = true
+				return false
+			}
+			return true
+		}
 	}
-}
+	matched, pathParams := router.match(groups, r.URL.Path, accept(r.Method))
+	if matched == nil && r.Method == http.MethodHead {
+		// GET endpoints answer HEAD requests with their headers only
+		matched, pathParams = router.match(groups, r.URL.Path, accept(http.MethodGet))
+		w = headResponseWriter{w}
+	}
+	if matched != nil {
+		ep := matched.endpoint
+		s.Logger.DebugContext(r.Context(), "Matched endpoint", map[string]any{
+			"path":    r.URL.Path,
+			"method":  r.Method,
+			"pattern": ep.Path,
+			"params":  pathParams,
+		})
 
-// Clear clears the cache
-func (c *ResponseCache) Clear() {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.cache = make(map[string]cachedResponse)
-}
+		middleware.SetRoute(r, ep.ID, ep.Path)
 
-// Server represents the JSON server
-type Server struct {
-	Config      *config.Config
-	Logger      *logger.Logger
-	Cache       *ResponseCache
-	CacheTTL    time.Duration
-	PathParams  map[string][]string
-	paramRegexp *regexp.Regexp
-}
+		// Store path params in context
+		ctx := context.WithValue(r.Context(), PathParamsKey, pathParams)
+		r = r.WithContext(ctx)
 
-// NewServer creates a new server instance
-func NewServer(cfg *config.Config, log *logger.Logger, cacheTTL time.Duration) *Server {
-	s := &Server{
-		Config:      cfg,
-		Logger:      log,
-		Cache:       NewResponseCache(),
-		CacheTTL:    cacheTTL,
-		PathParams:  make(map[string][]string),
-		paramRegexp: regexp.MustCompile(`:([\w]+)`),
-	}
-
-	// Pre-process endpoints to find path parameters
-	for _, ep := range cfg.GetEndpoints() {
-		if ep.Folder == "" { // Only for API endpoints, not static file servers
-			params := s.extractPathParams(ep.Path)
-			if len(params) > 0 {
-				s.PathParams[ep.Path] = params
+		// Client certificate details are available to response templates
+		params := pathParams
+		if cert := clientCertificate(r); cert != nil {
+			params = clientCertParams(cert)
+			for name, value := range pathParams {
+				params[name] = value
 			}
 		}
+
+		s.protect(ep, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			s.serveEndpoint(w, r, ep, params)
+		})).ServeHTTP(w, r)
+		return
 	}
 
-	return s
-}
+	if certRejected {
+		w.Header().Set("Content-Type", MIMEApplicationJSONUTF8)
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error": "Client certificate not accepted"}`))
+		return
+	}
 
-// extractPathParams extracts parameter names from a path pattern
-// e.g., "/users/:id" returns ["id"]
-func (s *Server) extractPathParams(path string) []string {
-	matches := s.paramRegexp.FindAllStringSubmatch(path, -1)
-	params := make([]string, 0, len(matches))
+	// The path exists but not for this method
+	if methods := router.allowedMethods(groups, r.URL.Path); len(methods) > 0 {
+		w.Header().Set("Allow", strings.Join(allowList(methods), ", "))
 
-	for _, match := range matches {
-		if len(match) > 1 {
-			params = append(params, match[1])
+		// CORS preflights also get their headers from the CORS middleware
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
 		}
+
+		w.Header().Set("Content-Type", MIMEApplicationJSONUTF8)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		w.Write([]byte(`{"error": "Method not allowed"}`))
+		return
 	}
 
-	return params
+	// If we got here, no endpoint matched
+	w.Header().Set("Content-Type", MIMEApplicationJSONUTF8)
+	w.WriteHeader(http.StatusNotFound)
+	w.Write([]byte(`{"error": "Not found"}`))
 }
 
-// matchPath checks if a request path matches a route pattern
-// and extracts path parameters if present
-func (s *Server) matchPath(pattern, path string) (bool, map[string]string) {
-	// Check for exact match (no parameters)
-	if pattern == path {
-		return true, nil
+// allowList adds the methods answered automatically to the methods of the
+// matching endpoints
+func allowList(methods []string) []string {
+	allowed := append([]string{http.MethodOptions}, methods...)
+	if slices.Contains(methods, http.MethodGet) {
+		allowed = append(allowed, http.MethodHead)
 	}
+	slices.Sort(allowed)
+	return slices.Compact(allowed)
+}
+
+// ResolveCORS is the resolver of middleware.CORS. It returns the policy of
+// the endpoint serving a request, or of the endpoint a preflight request asks
+// about, and the methods the path supports.
+func (s *Server) ResolveCORS(r *http.Request) (*config.CORSConfig, []string) {
+	router := s.Router()
+	groups := router.forHost(r.Host)
+	acceptAll := func(*route) bool { return true }
 
-	// Check if this pattern has registered parameters
-	_, hasParams := s.PathParams[pattern]
-	if !hasParams {
-		return false, nil
+	if folder := router.matchFolder(groups, r.URL.Path, acceptAll); folder != nil {
+		return s.Config.ResolveCORS(&folder.endpoint), []string{http.MethodGet, http.MethodHead, http.MethodOptions}
 	}
 
-	// Convert pattern to regexp
-	patternParts := strings.Split(pattern, "/")
-	pathParts := strings.Split(path, "/")
+	method := r.Method
+	if requested := r.Header.Get("Access-Control-Request-Method"); method == http.MethodOptions && requested != "" {
+		method = requested
+	}
+	matched, _ := router.match(groups, r.URL.Path, func(rt *route) bool {
+		return rt.endpoint.Method == method || (method == http.MethodHead && rt.endpoint.Method == http.MethodGet)
+	})
 
-	if len(patternParts) != len(pathParts) {
-		return false, nil
+	var methods []string
+	if found := router.allowedMethods(groups, r.URL.Path); len(found) > 0 {
+		methods = allowList(found)
 	}
+	if matched == nil {
+		return s.Config.ResolveCORS(nil), methods
+	}
+	return s.Config.ResolveCORS(&matched.endpoint), methods
+}
 
-	paramValues := make(map[string]string)
+// headResponseWriter drops the body of a GET endpoint answering a HEAD request
+type headResponseWriter struct {
+	http.ResponseWriter
+}
 
-	for i, part := range patternParts {
-		if strings.HasPrefix(part, ":") {
-			// This is a parameter
-			paramName := part[1:] // Remove the colon
-			paramValues[paramName] = pathParts[i]
-		} else if part != pathParts[i] {
-			// Non-parameter parts must match exactly
-			return false, nil
-		}
+// Write discards the body
+func (w headResponseWriter) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+// protect wraps an endpoint handler with the rate limit and auth settings that apply to it
+func (s *Server) protect(ep config.Endpoint, next http.Handler) http.Handler {
+	if auth := s.Config.ResolveAuth(ep); auth != nil {
+		next = middleware.Auth(*auth, s.TokenVerifier)(next)
+	}
+	if ep.RateLimit != nil {
+		next = middleware.RateLimit(s.rateLimiter(ep))(next)
 	}
+	return next
+}
+
+// globalLimiterKey identifies the limiter of the global rate limit
+const globalLimiterKey = "global"
 
-	return true, paramValues
+// ResolveRateLimiter returns the limiter of the global rate limit in the current
+// configuration, or nil when there is none, so that reloads take effect
+func (s *Server) ResolveRateLimiter(r *http.Request) *middleware.RateLimiter {
+	policy := s.Config.GetRateLimit()
+	if policy == nil {
+		return nil
+	}
+	return s.limiter(globalLimiterKey, *policy)
 }
 
-// HandleRequest handles all HTTP requests
-func (s *Server) HandleRequest(w http.ResponseWriter, r *http.Request) {
-	// Set CORS headers (this is also done in middleware, but useful as a fallback)
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Credentials", "true")
-	w.Header().Set("Access-Control-Allow-Headers", "Origin, X-Requested-With, Content-Type, Accept, Authorization")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
-
-	// Handle OPTIONS requests
-	if r.Method == http.MethodOptions {
-		w.WriteHeader(http.StatusOK)
-		return
+// rateLimiter returns the limiter tracking an endpoint's quota
+func (s *Server) rateLimiter(ep config.Endpoint) *middleware.RateLimiter {
+	return s.limiter(limiterKey(ep), *ep.RateLimit)
+}
+
+// limiterKey identifies the limiter of an endpoint
+func limiterKey(ep config.Endpoint) string {
+	return ep.ID + " " + ep.Method + " " + ep.Path
+}
+
+// limiter returns the limiter stored under key.
+// Limiters persist across requests and are replaced when the policy changes on reload.
+// Idle clients are discarded by a background sweep that runs while limiters are in use.
+func (s *Server) limiter(key string, policy config.RateLimitConfig) *middleware.RateLimiter {
+	s.limitersMu.Lock()
+	defer s.limitersMu.Unlock()
+
+	limiter, ok := s.limiters[key]
+	if !ok || limiter.Policy() != policy {
+		limiter = middleware.NewRateLimiter(policy)
+		s.limiters[key] = limiter
+	}
+	if !s.limitersSweeping {
+		s.limitersSweeping = true
+		go s.sweepLimiters(s.Cache.SweepInterval)
 	}
+	return limiter
+}
 
-	// Check for file server endpoints first
-	for _, ep := range s.Config.GetEndpoints() {
-		if ep.Folder != "" && strings.HasPrefix(r.URL.Path, ep.Path) {
-			// This is a static file server endpoint
-			fileServer := http.StripPrefix(ep.Path, http.FileServer(http.Dir(ep.Folder)))
-			fileServer.ServeHTTP(w, r)
-			return
+// pruneLimiters drops the limiters of endpoints that are gone or no longer
+// rate limited, and of the global limit when it was removed
+func (s *Server) pruneLimiters(endpoints []config.Endpoint) {
+	keep := make(map[string]bool)
+	for _, ep := range endpoints {
+		if ep.RateLimit != nil {
+			keep[limiterKey(ep)] = true
 		}
 	}
+	if s.Config.GetRateLimit() != nil {
+		keep[globalLimiterKey] = true
+	}
+
+	s.limitersMu.Lock()
+	defer s.limitersMu.Unlock()
 
-	// Handle API endpoints
-	for _, ep := range s.Config.GetEndpoints() {
-		if ep.Folder != "" {
-			continue // Skip file server endpoints
+	for key := range s.limiters {
+		if !keep[key] {
+			delete(s.limiters, key)
+		}
+	}
+}
+
+// sweepLimiters periodically discards idle clients from the limiters and
+// stops once no limiter tracks a client, like the response cache sweep
+func (s *Server) sweepLimiters(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.limitersMu.Lock()
+		clients := 0
+		for _, limiter := range s.limiters {
+			clients += limiter.Sweep()
+		}
+		if clients == 0 {
+			s.limitersSweeping = false
+			s.limitersMu.Unlock()
+			return
 		}
+		s.limitersMu.Unlock()
+	}
+}
 
-		// Check if path matches (with or without params)
-		match, pathParams := s.matchPath(ep.Path, r.URL.Path)
+// serveEndpoint writes the response of a matched API endpoint
+func (s *Server) serveEndpoint(w http.ResponseWriter, r *http.Request, ep config.Endpoint, pathParams map[string]string) {
+	locale := s.responseLocale(r, ep)
+	status, jsonPath, localized := ep.LocalizedResponse(locale)
+
+	// Try to get response from cache
+	policy := s.Config.ResolveCache(&ep)
+	key := cacheKey(r, policy)
+	if len(ep.Hosts) > 0 {
+		// Virtual hosts may serve different responses for the same path
+		key = ep.ID + ":" + key
+	}
+	if fingerprint, ok := pathParams[ClientCertParamPrefix+"fingerprint"]; ok {
+		// Responses may be templated with the client certificate
+		key += "\ncert: " + fingerprint
+	}
+	if localized {
+		key += "\nlocale: " + locale
+	}
 
-		if match && ep.Method == r.Method {
-			s.Logger.Debug("Matched endpoint", map[string]any{
-				"path":    r.URL.Path,
-				"method":  r.Method,
-				"pattern": ep.Path,
-				"params":  pathParams,
-			})
+	var respBody []byte
+	found := false
+	if !policy.Disabled {
+		respBody, found = s.Cache.Get(key)
+		if s.Metrics != nil {
+			if found {
+				s.Metrics.CacheHits.Inc()
+			} else {
+				s.Metrics.CacheMisses.Inc()
+			}
+		}
+	}
 
-			// Store path params in context
-			ctx := context.WithValue(r.Context(), PathParamsKey, pathParams)
-			r = r.WithContext(ctx)
+	if !found {
+		var err error
+		respBody, err = s.getResponse(r.Context(), ep, jsonPath, pathParams)
+		if err != nil {
+			s.Logger.ErrorContext(r.Context(), "Error getting response", map[string]any{
+				"error": err.Error(),
+				"path":  jsonPath,
+			})
 
-			// Set headers
 			w.Header().Set("Content-Type", MIMEApplicationJSONUTF8)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error": "Internal server error"}`))
+			return
+		}
 
-			// Try to get response from cache
-			cacheKey := fmt.Sprintf("%s:%s", r.Method, r.URL.Path)
-			if cachedResponse, found := s.Cache.Get(cacheKey); found {
-				w.WriteHeader(ep.Status)
-				w.Write(cachedResponse)
-				return
+		// Cache the response for future requests, remembering its file so
+		// that edits to the file invalidate it
+		if !policy.Disabled {
+			file := jsonPath
+			if file != "" {
+				if abs, err := filepath.Abs(jsonPath); err == nil {
+					file = abs
+				}
 			}
-
-			// Get JSON response
-			respBody, err := s.getJSONResponse(ep.JsonPath, pathParams)
-			if err != nil {
-				s.Logger.Error("Error getting JSON response", map[string]any{
-					"error": err.Error(),
-					"path":  ep.JsonPath,
-				})
-
-				w.WriteHeader(http.StatusInternalServerError)
-				w.Write([]byte(`{"error": "Internal server error"}`))
-				return
+			ttl := s.CacheTTL
+			if policy.TTL > 0 {
+				ttl = time.Duration(policy.TTL) * time.Second
 			}
+			s.Cache.Set(key, file, respBody, ttl)
+		}
+	}
 
-			// Write response
-			w.WriteHeader(ep.Status)
-			w.Write(respBody)
-
-			// Cache the response for future requests
-			s.Cache.Set(cacheKey, respBody, s.CacheTTL)
+	// JSON responses are converted to the format the client asks for
+	contentType := responseContentType(ep, jsonPath, respBody)
+	if isJSONContentType(contentType) && len(respBody) > 0 {
+		w.Header().Add("Vary", "Accept")
+		f, body, err := negotiateFormat(r, respBody)
+		if err != nil {
+			w.Header().Set("Content-Type", MIMEApplicationJSONUTF8)
+			w.WriteHeader(http.StatusNotAcceptable)
+			w.Write([]byte(`{"error": "Not acceptable"}`))
 			return
 		}
+		if f != format.JSON {
+			contentType, respBody = f.ContentType, body
+		}
 	}
 
-	// If we got here, no endpoint matched
-	w.Header().Set("Content-Type", MIMEApplicationJSONUTF8)
-	w.WriteHeader(http.StatusNotFound)
-	w.Write([]byte(`{"error": "Not found"}`))
+	// Write response
+	w.Header().Set("Content-Type", contentType)
+	if len(ep.Locales) > 0 {
+		w.Header().Add("Vary", "Accept-Language")
+	}
+	if localized {
+		w.Header().Set("Content-Language", locale)
+	}
+	if ep.Download != "" {
+		w.Header().Set("Content-Disposition", contentDisposition(ep.Download))
+	}
+	setResponseHeaders(w.Header(), ep, pathParams)
+	w.Header().Set("Content-Length", strconv.Itoa(len(respBody)))
+	w.WriteHeader(status)
+	w.Write(respBody)
 }
 
-// getJSONResponse gets the JSON response for an endpoint
-func (s *Server) getJSONResponse(jsonPath string, pathParams map[string]string) ([]byte, error) {
-	file, err := os.Open(jsonPath)
-	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrJSONFileNotFound, err)
-	}
-	defer file.Close()
+// getResponse reads the response body of an endpoint from its response file
+// or inline body and substitutes path parameters in text bodies
+func (s *Server) getResponse(ctx context.Context, ep config.Endpoint, jsonPath string, pathParams map[string]string) ([]byte, error) {
+	var content []byte
+	if jsonPath != "" {
+		file, err := os.Open(jsonPath)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrJSONFileNotFound, err)
+		}
+		defer file.Close()
 
-	content, err := io.ReadAll(file)
-	if err != nil {
-		return nil, fmt.Errorf("error reading JSON file: %w", err)
+		content, err = io.ReadAll(file)
+		if err != nil {
+			return nil, fmt.Errorf("error reading response file: %w", err)
+		}
+	} else {
+		var err error
+		content, err = ep.InlineBody()
+		if err != nil {
+			return nil, fmt.Errorf("error decoding inline body: %w", err)
+		}
 	}
 
 	// If no path parameters, return the content as is
@@ -287,18 +489,23 @@ func (s *Server) getJSONResponse(jsonPath string, pathParams map[string]string)
 		return content, nil
 	}
 
-	// Replace path parameters in the JSON content if needed
-	contentStr := string(content)
-	for param, value := range pathParams {
-		placeholder := fmt.Sprintf(":%s", param)
-		contentStr = strings.ReplaceAll(contentStr, placeholder, value)
+	// Binary content is never templated
+	contentType := responseContentType(ep, jsonPath, content)
+	if !isTextContentType(contentType) {
+		return content, nil
+	}
+
+	// Replace path parameters in the content
+	contentStr := substituteParams(string(content), pathParams)
+	if !isJSONContentType(contentType) {
+		return []byte(contentStr), nil
 	}
 
 	// Validate that the result is still valid JSON
 	var jsonObj interface{}
 	if err := json.Unmarshal([]byte(contentStr), &jsonObj); err != nil {
 		// If parameter replacement made the JSON invalid, return the original
-		s.Logger.Warn("Parameter replacement resulted in invalid JSON", map[string]any{
+		s.Logger.WarnContext(ctx, "Parameter replacement resulted in invalid JSON", map[string]any{
 			"error": err.Error(),
 			"path":  jsonPath,
 		})
@@ -313,3 +520,9 @@ func (s *Server) ClearCache() {
 	s.Cache.Clear()
 	s.Logger.Info("Response cache cleared")
 }
+
+// InvalidateFile drops the cached responses rendered from a response file
+func (s *Server) InvalidateFile(file string) {
+	removed := s.Cache.InvalidateFile(file)
+	s.Logger.Debug("Cached responses invalidated", map[string]any{"file": file, "entries": removed})
+}
@@ -0,0 +1,548 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tkc/go-json-server/src/config"
+	"github.com/tkc/go-json-server/src/logger"
+	"github.com/tkc/go-json-server/src/middleware"
+)
+
+// newTestServer returns a server for endpoints that all answer with the same JSON body
+func newTestServer(t *testing.T, body string, endpoints ...config.Endpoint) *Server {
+	jsonFile := filepath.Join(t.TempDir(), "response.json")
+	assert.NoError(t, os.WriteFile(jsonFile, []byte(body), 0644))
+	for i := range endpoints {
+		endpoints[i].JsonPath = jsonFile
+	}
+
+	log, err := logger.NewLogger(logger.LogConfig{Level: logger.LevelError})
+	assert.NoError(t, err)
+	return NewServer(&config.Config{Endpoints: endpoints}, log, time.Minute)
+}
+
+func TestServer_Methods(t *testing.T) {
+	server := newTestServer(t, `{"ok": true}`,
+		config.Endpoint{Method: "GET", Status: 200, Path: "/users"},
+		config.Endpoint{Method: "POST", Status: 201, Path: "/users"},
+		config.Endpoint{Method: "DELETE", Status: 204, Path: "/users/:id"},
+	)
+
+	do := func(method, path string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		rec := httptest.NewRecorder()
+		server.HandleRequest(rec, req)
+		return rec
+	}
+
+	t.Run("method not allowed", func(t *testing.T) {
+		rec := do(http.MethodPut, "/users", nil)
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+		assert.Equal(t, "GET, HEAD, OPTIONS, POST", rec.Header().Get("Allow"))
+
+		rec = do(http.MethodGet, "/users/7", nil)
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+		assert.Equal(t, "DELETE, OPTIONS", rec.Header().Get("Allow"))
+	})
+
+	t.Run("head", func(t *testing.T) {
+		get := do(http.MethodGet, "/users", nil)
+		rec := do(http.MethodHead, "/users", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Body.String())
+		assert.Equal(t, get.Header().Get("Content-Type"), rec.Header().Get("Content-Type"))
+		assert.Equal(t, get.Header().Get("Content-Length"), rec.Header().Get("Content-Length"))
+		assert.NotEmpty(t, rec.Header().Get("Content-Length"))
+
+		// HEAD is only implied by GET
+		rec = do(http.MethodHead, "/users/7", nil)
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	})
+
+	t.Run("options", func(t *testing.T) {
+		rec := do(http.MethodOptions, "/users/7", http.Header{"Access-Control-Request-Method": {"DELETE"}})
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, "DELETE, OPTIONS", rec.Header().Get("Allow"))
+	})
+
+	t.Run("unknown path", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, do(http.MethodGet, "/orders", nil).Code)
+		assert.Equal(t, http.StatusNotFound, do(http.MethodOptions, "/orders", nil).Code)
+	})
+}
+
+// stubVerifier accepts a single bearer token
+type stubVerifier string
+
+func (v stubVerifier) VerifyToken(token string) (map[string]any, error) {
+	if token != string(v) {
+		return nil, errors.New("invalid token")
+	}
+	return map[string]any{"sub": "user-1"}, nil
+}
+
+func TestServer_Auth(t *testing.T) {
+	server := newTestServer(t, `{"ok": true}`,
+		config.Endpoint{Method: "GET", Status: 200, Path: "/private"},
+		config.Endpoint{Method: "GET", Status: 200, Path: "/public", Auth: &config.AuthConfig{Disabled: true}},
+		config.Endpoint{Method: "GET", Status: 200, Path: "/token", Auth: &config.AuthConfig{Bearer: true}},
+	)
+	server.Config.Auth = &config.AuthConfig{APIKeys: []string{"secret"}}
+	server.TokenVerifier = stubVerifier("valid-token")
+
+	do := func(path string, header http.Header) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		rec := httptest.NewRecorder()
+		server.HandleRequest(rec, req)
+		return rec.Code
+	}
+
+	t.Run("global default", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, do("/private", nil))
+		assert.Equal(t, http.StatusUnauthorized, do("/private", http.Header{"X-Api-Key": {"wrong"}}))
+		assert.Equal(t, http.StatusOK, do("/private", http.Header{"X-Api-Key": {"secret"}}))
+	})
+
+	t.Run("endpoint opts out", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, do("/public", nil))
+	})
+
+	t.Run("bearer token", func(t *testing.T) {
+		// The endpoint's auth replaces the global API key
+		assert.Equal(t, http.StatusUnauthorized, do("/token", http.Header{"X-Api-Key": {"secret"}}))
+		assert.Equal(t, http.StatusUnauthorized, do("/token", http.Header{"Authorization": {"Bearer forged"}}))
+		assert.Equal(t, http.StatusOK, do("/token", http.Header{"Authorization": {"Bearer valid-token"}}))
+	})
+}
+
+func TestServer_RateLimit(t *testing.T) {
+	limit := &config.RateLimitConfig{Limit: 1, Window: 60}
+	server := newTestServer(t, `{"ok": true}`,
+		config.Endpoint{Method: "GET", Status: 200, Path: "/users", RateLimit: limit},
+		config.Endpoint{Method: "POST", Status: 201, Path: "/users", RateLimit: limit},
+		config.Endpoint{Method: "GET", Status: 200, Path: "/posts"},
+	)
+
+	do := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		server.HandleRequest(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+
+	t.Run("endpoint limit", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, do(http.MethodGet, "/users").Code)
+
+		rec := do(http.MethodGet, "/users")
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+		assert.Equal(t, "1", rec.Header().Get("RateLimit-Limit"))
+
+		// Quotas are kept per endpoint ID, method and path
+		assert.Equal(t, http.StatusCreated, do(http.MethodPost, "/users").Code)
+		assert.Equal(t, http.StatusOK, do(http.MethodGet, "/posts").Code)
+		assert.Len(t, server.limiters, 2)
+	})
+
+	t.Run("changed policy", func(t *testing.T) {
+		// A reloaded policy starts a fresh limiter
+		ep := server.Config.Endpoints[0]
+		before := server.rateLimiter(ep)
+		ep.RateLimit = &config.RateLimitConfig{Limit: 5, Window: 60}
+		assert.NotSame(t, before, server.rateLimiter(ep))
+	})
+
+	t.Run("global limit follows the config", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/posts", nil)
+		assert.Nil(t, server.ResolveRateLimiter(req))
+
+		server.Config.RateLimit = &config.RateLimitConfig{Limit: 2, Window: 60}
+		limiter := server.ResolveRateLimiter(req)
+		assert.NotNil(t, limiter)
+		assert.Same(t, limiter, server.ResolveRateLimiter(req))
+
+		server.Config.RateLimit = &config.RateLimitConfig{Limit: 3, Window: 60}
+		assert.NotSame(t, limiter, server.ResolveRateLimiter(req))
+		assert.Equal(t, 3, server.ResolveRateLimiter(req).Policy().Limit)
+
+		server.Config.RateLimit = nil
+		assert.Nil(t, server.ResolveRateLimiter(req))
+	})
+
+	t.Run("reload prunes limiters", func(t *testing.T) {
+		ep, err := server.Config.AddEndpoint(config.Endpoint{Method: "GET", Status: 200, Path: "/orders", JsonPath: server.Config.Endpoints[0].JsonPath, RateLimit: limit})
+		assert.NoError(t, err)
+		server.Config.RateLimit = &config.RateLimitConfig{Limit: 2, Window: 60}
+		server.ResolveRateLimiter(httptest.NewRequest(http.MethodGet, "/orders", nil))
+		assert.Equal(t, http.StatusOK, do(http.MethodGet, "/orders").Code)
+
+		server.limitersMu.Lock()
+		assert.Contains(t, server.limiters, limiterKey(ep))
+		assert.Contains(t, server.limiters, globalLimiterKey)
+		server.limitersMu.Unlock()
+
+		// Removed endpoints and a removed global limit drop their limiters
+		assert.NoError(t, server.Config.DeleteEndpoint(ep.ID))
+		server.Config.RateLimit = nil
+		server.Rebuild()
+
+		server.limitersMu.Lock()
+		defer server.limitersMu.Unlock()
+		assert.NotContains(t, server.limiters, limiterKey(ep))
+		assert.NotContains(t, server.limiters, globalLimiterKey)
+		assert.Len(t, server.limiters, 2)
+	})
+}
+
+func TestServer_SweepLimiters(t *testing.T) {
+	server := newTestServer(t, `{"ok": true}`,
+		config.Endpoint{Method: "GET", Status: 200, Path: "/users", RateLimit: &config.RateLimitConfig{Limit: 1, Window: 1}},
+	)
+	server.Cache.SweepInterval = 10 * time.Millisecond
+
+	rec := httptest.NewRecorder()
+	server.HandleRequest(rec, httptest.NewRequest(http.MethodGet, "/users", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// Idle clients are evicted and the sweep stops once none remain
+	assert.Eventually(t, func() bool {
+		server.limitersMu.Lock()
+		defer server.limitersMu.Unlock()
+		return !server.limitersSweeping
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestServer_ResolveCORS(t *testing.T) {
+	server := newTestServer(t, `{"ok": true}`,
+		config.Endpoint{Method: "GET", Status: 200, Path: "/users"},
+		config.Endpoint{Method: "POST", Status: 201, Path: "/users", CORS: &config.CORSConfig{
+			AllowOrigins:     []string{"https://admin.local"},
+			AllowCredentials: true,
+		}},
+	)
+	server.Config.CORS = &config.CORSConfig{AllowOrigins: []string{"*"}}
+	handler := middleware.CORSPolicy(server.ResolveCORS)(http.HandlerFunc(server.HandleRequest))
+
+	do := func(method, origin string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/users", nil)
+		req.Header.Set("Origin", origin)
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// The server policy applies to endpoints without their own
+	rec := do(http.MethodGet, "https://app.local", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+
+	// Preflights use the policy of the endpoint they ask about and list the real methods
+	rec = do(http.MethodOptions, "https://admin.local", http.Header{"Access-Control-Request-Method": {"POST"}})
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "https://admin.local", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "GET, HEAD, OPTIONS, POST", rec.Header().Get("Access-Control-Allow-Methods"))
+
+	rec = do(http.MethodPost, "https://app.local", nil)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+
+	// Without a cors block every origin is allowed without credentials
+	server.Config.CORS = nil
+	rec = do(http.MethodGet, "https://app.local", nil)
+	assert.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+func TestServer_ResponseBodies(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+	png := "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+
+	endpoints := []config.Endpoint{
+		{Method: "GET", Status: 200, Path: "/legacy/:id", JsonPath: write("user.xml", `<user id=":id"/>`)},
+		{Method: "GET", Status: 200, Path: "/exports/:id", JsonPath: write("users.csv", "id,name\n:id,alice\n"), Download: "users-export.csv"},
+		{Method: "GET", Status: 200, Path: "/logo/:id", JsonPath: write("logo.png", png+":id")},
+		{Method: "GET", Status: 200, Path: "/status", Type: "text", Body: "OK"},
+		{Method: "GET", Status: 200, Path: "/pixel", BodyBase64: "iVBORw0KGgoAAAANSUhEUg=="},
+		{Method: "GET", Status: 200, Path: "/report", ContentType: "application/pdf", Body: "%PDF-1.4", Download: "Bericht März.pdf"},
+		{Method: "DELETE", Status: 204, Path: "/users/:id"},
+	}
+	log, err := logger.NewLogger(logger.LogConfig{Level: logger.LevelError})
+	assert.NoError(t, err)
+	server := NewServer(&config.Config{Endpoints: endpoints}, log, time.Minute)
+
+	tests := []struct {
+		method, path string
+		status       int
+		contentType  string
+		disposition  string
+		body         string
+	}{
+		{"GET", "/legacy/7", 200, "application/xml; charset=UTF-8", "", `<user id="7"/>`},
+		{"GET", "/exports/7", 200, "text/csv; charset=UTF-8", `attachment; filename=users-export.csv`, "id,name\n7,alice\n"},
+		{"GET", "/logo/7", 200, "image/png", "", png + ":id"},
+		{"GET", "/status", 200, MIMETextPlainUTF8, "", "OK"},
+		{"GET", "/pixel", 200, "image/png", "", png},
+		{"GET", "/report", 200, "application/pdf", `attachment; filename*=utf-8''Bericht%20M%C3%A4rz.pdf`, "%PDF-1.4"},
+		{"DELETE", "/users/7", 204, MIMEApplicationJSONUTF8, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			// The second request is served from the cache
+			for range 2 {
+				rec := httptest.NewRecorder()
+				server.HandleRequest(rec, httptest.NewRequest(tt.method, tt.path, nil))
+				assert.Equal(t, tt.status, rec.Code)
+				assert.Equal(t, tt.contentType, rec.Header().Get("Content-Type"))
+				assert.Equal(t, tt.disposition, rec.Header().Get("Content-Disposition"))
+				assert.Equal(t, tt.body, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestServer_ResponseHeaders(t *testing.T) {
+	server := newTestServer(t, `{"id": ":id"}`,
+		config.Endpoint{
+			Method: "POST", Status: 201, Path: "/orgs/:org/users/:id",
+			Headers: map[string]string{
+				"Location":      "/orgs/:org/users/:id",
+				"X-Total-Count": "42",
+				"Link":          `</orgs/:org/users?page=2>; rel="next"`,
+			},
+			Cookies: map[string]config.CookieConfig{
+				"session": {Value: "session-:id", Path: "/", MaxAge: 3600, HttpOnly: true, Secure: true, SameSite: "Lax"},
+				"legacy":  {Value: "", MaxAge: -1},
+			},
+		},
+		config.Endpoint{
+			Method: "GET", Status: 302, Path: "/go/:target",
+			Headers: map[string]string{"Location": "https://example.com/:target", "Content-Type": MIMETextPlainUTF8},
+		},
+	)
+
+	rec := httptest.NewRecorder()
+	server.HandleRequest(rec, httptest.NewRequest(http.MethodPost, "/orgs/acme/users/7", nil))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "/orgs/acme/users/7", rec.Header().Get("Location"))
+	assert.Equal(t, "42", rec.Header().Get("X-Total-Count"))
+	assert.Equal(t, `</orgs/acme/users?page=2>; rel="next"`, rec.Header().Get("Link"))
+	assert.Equal(t, []string{
+		"legacy=; Max-Age=0",
+		"session=session-7; Path=/; Max-Age=3600; HttpOnly; Secure; SameSite=Lax",
+	}, rec.Header().Values("Set-Cookie"))
+
+	// Configured headers take precedence over the derived content type
+	rec = httptest.NewRecorder()
+	server.HandleRequest(rec, httptest.NewRequest(http.MethodGet, "/go/docs", nil))
+	assert.Equal(t, http.StatusFound, rec.Code)
+	assert.Equal(t, "https://example.com/docs", rec.Header().Get("Location"))
+	assert.Equal(t, MIMETextPlainUTF8, rec.Header().Get("Content-Type"))
+}
+
+func TestServer_ContentNegotiation(t *testing.T) {
+	server := newTestServer(t, `[{"id": ":id", "name": "Alice"}]`,
+		config.Endpoint{Method: "GET", Status: 200, Path: "/users/:id"},
+	)
+
+	get := func(path, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		rec := httptest.NewRecorder()
+		server.HandleRequest(rec, req)
+		return rec
+	}
+
+	rec := get("/users/7", "")
+	assert.Equal(t, MIMEApplicationJSONUTF8, rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `[{"id": "7", "name": "Alice"}]`, rec.Body.String())
+	assert.Equal(t, "Accept", rec.Header().Get("Vary"))
+
+	rec = get("/users/7", "text/csv")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/csv; charset=UTF-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "id,name\n7,Alice\n", rec.Body.String())
+	assert.Equal(t, strconv.Itoa(rec.Body.Len()), rec.Header().Get("Content-Length"))
+
+	// The query parameter takes precedence over the Accept header
+	rec = get("/users/7?_format=yaml", "text/csv")
+	assert.Equal(t, "application/yaml; charset=UTF-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "- id: \"7\"\n  name: Alice\n", rec.Body.String())
+
+	// JSON based media types are served JSON
+	for _, accept := range []string{"application/vnd.api+json", "application/problem+json"} {
+		rec = get("/users/7", accept)
+		assert.Equal(t, http.StatusOK, rec.Code, accept)
+		assert.Equal(t, MIMEApplicationJSONUTF8, rec.Header().Get("Content-Type"), accept)
+		assert.JSONEq(t, `[{"id": "7", "name": "Alice"}]`, rec.Body.String(), accept)
+	}
+
+	// An Accept header naming no supported format gets JSON
+	rec = get("/users/7", "image/png")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, MIMEApplicationJSONUTF8, rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `[{"id": "7", "name": "Alice"}]`, rec.Body.String())
+
+	// Formats asked for by name must exist
+	assert.Equal(t, http.StatusNotAcceptable, get("/users/7?_format=toml", "").Code)
+}
+
+func TestServer_Locales(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+
+	cfg := &config.Config{
+		Localization: config.LocalizationConfig{DefaultLocale: "en"},
+		Endpoints: []config.Endpoint{{
+			Method: "GET", Status: 200, Path: "/greeting",
+			JsonPath: write("greeting.json", `{"text": "default"}`),
+			Locales: map[string]string{
+				"en": write("greeting.en.json", `{"text": "Hello"}`),
+				"ja": write("greeting.ja.json", `{"text": "こんにちは"}`),
+			},
+		}, {
+			Method: "GET", Status: 200, Path: "/farewell",
+			JsonPath: write("farewell.json", `{"text": "default"}`),
+			Locales:  map[string]string{"ja": write("farewell.ja.json", `{"text": "さようなら"}`)},
+			Variants: map[string]config.ResponseVariant{
+				"maintenance": {Status: 503, JsonPath: write("maintenance.json", `{"text": "down"}`)},
+			},
+			Variant: "maintenance",
+		}},
+	}
+	log, err := logger.NewLogger(logger.LogConfig{Level: logger.LevelError})
+	assert.NoError(t, err)
+	server := NewServer(cfg, log, time.Minute)
+
+	get := func(path, acceptLanguage string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if acceptLanguage != "" {
+			req.Header.Set("Accept-Language", acceptLanguage)
+		}
+		rec := httptest.NewRecorder()
+		server.HandleRequest(rec, req)
+		return rec
+	}
+
+	tests := []struct {
+		path, acceptLanguage, language, text string
+	}{
+		{"/greeting", "ja-JP,ja;q=0.9,en;q=0.8", "ja", "こんにちは"},
+		{"/greeting", "fr-CA, en-US;q=0.5", "en", "Hello"},
+		{"/greeting", "ja;q=0, fr", "en", "Hello"},
+		{"/greeting", "", "en", "Hello"},
+		{"/greeting?lang=ja", "en", "ja", "こんにちは"},
+		{"/greeting?lang=ko", "ja", "ja", "こんにちは"},
+	}
+	for _, tt := range tests {
+		// Responses are cached per locale
+		rec := get(tt.path, tt.acceptLanguage)
+		assert.Equal(t, tt.language, rec.Header().Get("Content-Language"), tt.path+" "+tt.acceptLanguage)
+		assert.JSONEq(t, `{"text": "`+tt.text+`"}`, rec.Body.String())
+		assert.Contains(t, rec.Header().Values("Vary"), "Accept-Language")
+	}
+
+	// Without a default locale the endpoint's own response is served
+	cfg.Localization.DefaultLocale = ""
+	server.ClearCache()
+	rec := get("/greeting", "fr")
+	assert.Empty(t, rec.Header().Get("Content-Language"))
+	assert.JSONEq(t, `{"text": "default"}`, rec.Body.String())
+
+	// A variant with its own file takes precedence over the locale
+	rec = get("/farewell", "ja")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Empty(t, rec.Header().Get("Content-Language"))
+	assert.JSONEq(t, `{"text": "down"}`, rec.Body.String())
+}
+
+func TestServer_Cache(t *testing.T) {
+	server := newTestServer(t, `{"version": 1}`,
+		config.Endpoint{Method: "GET", Status: 200, Path: "/cached"},
+		config.Endpoint{Method: "GET", Status: 200, Path: "/uncached", Cache: &config.CacheConfig{Disabled: true}},
+	)
+	jsonFile := server.Config.Endpoints[0].JsonPath
+
+	get := func(path string) string {
+		rec := httptest.NewRecorder()
+		server.HandleRequest(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Body.String()
+	}
+
+	assert.Equal(t, `{"version": 1}`, get("/cached"))
+	assert.Equal(t, `{"version": 1}`, get("/uncached"))
+	assert.NoError(t, os.WriteFile(jsonFile, []byte(`{"version": 2}`), 0644))
+
+	// The cached endpoint keeps its response until invalidated
+	assert.Equal(t, `{"version": 1}`, get("/cached"))
+	assert.Equal(t, `{"version": 2}`, get("/uncached"))
+
+	// A different query is a different request
+	assert.Equal(t, `{"version": 2}`, get("/cached?page=2"))
+
+	stats := server.Cache.Stats()
+	assert.Equal(t, 2, stats.Entries)
+	assert.Equal(t, uint64(1), stats.Hits)
+	assert.Equal(t, uint64(2), stats.Misses)
+}
+
+func TestServer_InvalidateFile(t *testing.T) {
+	dir := t.TempDir()
+	usersFile := filepath.Join(dir, "users.json")
+	ordersFile := filepath.Join(dir, "orders.json")
+	assert.NoError(t, os.WriteFile(usersFile, []byte(`{"users": 1}`), 0644))
+	assert.NoError(t, os.WriteFile(ordersFile, []byte(`{"orders": 1}`), 0644))
+
+	log, err := logger.NewLogger(logger.LogConfig{Level: logger.LevelError})
+	assert.NoError(t, err)
+	server := NewServer(&config.Config{Endpoints: []config.Endpoint{
+		{Method: "GET", Status: 200, Path: "/users", JsonPath: usersFile},
+		{Method: "GET", Status: 200, Path: "/orders", JsonPath: ordersFile},
+	}}, log, time.Minute)
+
+	get := func(path string) string {
+		rec := httptest.NewRecorder()
+		server.HandleRequest(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Body.String()
+	}
+
+	assert.Equal(t, `{"users": 1}`, get("/users"))
+	assert.Equal(t, `{"orders": 1}`, get("/orders"))
+	assert.Equal(t, 2, server.Cache.Stats().Entries)
+
+	assert.NoError(t, os.WriteFile(usersFile, []byte(`{"users": 2}`), 0644))
+	assert.NoError(t, os.WriteFile(ordersFile, []byte(`{"orders": 2}`), 0644))
+
+	// Only the responses rendered from the invalidated file are dropped
+	server.InvalidateFile(usersFile)
+	assert.Equal(t, 1, server.Cache.Stats().Entries)
+	assert.Equal(t, `{"users": 2}`, get("/users"))
+	assert.Equal(t, `{"orders": 1}`, get("/orders"))
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/tkc/go-json-server/src/config"
+)
+
+// sameSiteModes maps the config's sameSite values to cookie modes
+var sameSiteModes = map[string]http.SameSite{
+	config.SameSiteLax:    http.SameSiteLaxMode,
+	config.SameSiteStrict: http.SameSiteStrictMode,
+	config.SameSiteNone:   http.SameSiteNoneMode,
+}
+
+// substituteParams replaces the :name placeholders of parameters in text.
+// Longer names go first so that :idx is not taken for :id followed by x.
+func substituteParams(text string, params map[string]string) string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	slices.SortFunc(names, func(a, b string) int { return len(b) - len(a) })
+
+	for _, name := range names {
+		text = strings.ReplaceAll(text, ":"+name, params[name])
+	}
+	return text
+}
+
+// setResponseHeaders adds the headers and cookies configured for an endpoint,
+// with parameters substituted in their values
+func setResponseHeaders(header http.Header, ep config.Endpoint, params map[string]string) {
+	for name, value := range ep.Headers {
+		header.Set(name, substituteParams(value, params))
+	}
+
+	names := make([]string, 0, len(ep.Cookies))
+	for name := range ep.Cookies {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		c := ep.Cookies[name]
+		cookie := &http.Cookie{
+			Name:     name,
+			Value:    substituteParams(c.Value, params),
+			Path:     c.Path,
+			Domain:   c.Domain,
+			MaxAge:   c.MaxAge,
+			Secure:   c.Secure,
+			HttpOnly: c.HttpOnly,
+			SameSite: sameSiteModes[strings.ToLower(c.SameSite)],
+		}
+		if v := cookie.String(); v != "" {
+			header.Add("Set-Cookie", v)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tkc/go-json-server/src/config"
+)
+
+// parseAcceptLanguage returns the language ranges of an Accept-Language
+// header value, most preferred first, leaving out those with q=0
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag     string
+		quality float64
+	}
+	var ranges []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			// A range is a token, which mime.ParseMediaType reads like a type without subtype
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		if quality > 0 {
+			ranges = append(ranges, weighted{tag, quality})
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].quality > ranges[j].quality })
+
+	tags := make([]string, len(ranges))
+	for i, r := range ranges {
+		tags[i] = r.tag
+	}
+	return tags
+}
+
+// responseLocale chooses the locale of an endpoint's response. A locale
+// requested with the query parameter is tried before Accept-Language.
+func (s *Server) responseLocale(r *http.Request, ep config.Endpoint) string {
+	if len(ep.Locales) == 0 {
+		return ""
+	}
+
+	localization := s.Config.GetLocalization()
+	ranges := parseAcceptLanguage(r.Header.Get("Accept-Language"))
+	if lang := r.URL.Query().Get(localization.QueryParam); lang != "" {
+		ranges = append([]string{lang}, ranges...)
+	}
+	return ep.MatchLocale(ranges, localization.DefaultLocale)
+}
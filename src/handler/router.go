@@ -0,0 +1,325 @@
+package handler
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/tkc/go-json-server/src/config"
+)
+
+// route is an endpoint registered with the router
+type route struct {
+	endpoint config.Endpoint
+
+	// group indexes the router's host groups
+	group int
+
+	// optional lists the parameters that a match may leave out; they are
+	// reported with empty values
+	optional []string
+}
+
+// node is a segment of the routing tree. Children are tried from the most to
+// the least specific: static text, constrained parameters, parameters and
+// finally catch-alls.
+type node struct {
+	static    map[string]*node
+	params    []*paramEdge
+	wildcards []*wildcardEdge
+
+	// routes end at this node, in configuration order
+	routes []*route
+}
+
+// paramEdge leads to the subtree of a parameter segment
+type paramEdge struct {
+	name    string
+	pattern *regexp.Regexp
+	node    *node
+}
+
+// wildcardEdge holds the routes ending in a catch-all segment
+type wildcardEdge struct {
+	name   string
+	routes []*route
+}
+
+// paramValue is a parameter captured while walking the tree
+type paramValue struct {
+	name  string
+	value string
+}
+
+// Router matches requests against one version of the configured endpoints.
+// It is never modified once built, so a request keeps using the router it
+// started with while a reload swaps in a new one.
+type Router struct {
+	generation uint64
+
+	// groups holds the host patterns of each distinct virtual host, with the
+	// endpoints without hosts in group 0. Every group has its own tree.
+	groups [][]string
+	trees  []*node
+
+	// folders are the static file endpoints in configuration order
+	folders []*route
+}
+
+// NewRouter compiles endpoints into a router. Disabled endpoints are left
+// out and paths are expected to have passed config validation.
+func NewRouter(endpoints []config.Endpoint) *Router {
+	r := &Router{groups: [][]string{nil}, trees: []*node{{}}}
+	groupIndex := map[string]int{"": 0}
+
+	for _, ep := range endpoints {
+		if ep.Disabled {
+			continue
+		}
+
+		key := config.HostsKey(ep.Hosts)
+		group, ok := groupIndex[key]
+		if !ok {
+			group = len(r.groups)
+			groupIndex[key] = group
+			r.groups = append(r.groups, ep.Hosts)
+			r.trees = append(r.trees, &node{})
+		}
+
+		rt := &route{endpoint: ep, group: group}
+		if ep.Folder != "" {
+			r.folders = append(r.folders, rt)
+			continue
+		}
+
+		segments, err := config.ParsePath(ep.Path)
+		if err != nil {
+			continue
+		}
+		for _, segment := range segments {
+			if segment.Optional {
+				rt.optional = append(rt.optional, segment.Value)
+			}
+		}
+		r.trees[group].insert(segments, rt)
+	}
+	return r
+}
+
+// insert adds a route under the path segments. Optional segments register
+// the route both with and without the segment.
+func (n *node) insert(segments []config.PathSegment, rt *route) {
+	if len(segments) == 0 {
+		n.routes = appendRoute(n.routes, rt)
+		return
+	}
+
+	segment := segments[0]
+	switch segment.Kind {
+	case config.SegmentStatic:
+		if n.static == nil {
+			n.static = make(map[string]*node)
+		}
+		child, ok := n.static[segment.Value]
+		if !ok {
+			child = &node{}
+			n.static[segment.Value] = child
+		}
+		child.insert(segments[1:], rt)
+
+	case config.SegmentParam:
+		n.param(segment).insert(segments[1:], rt)
+		if segment.Optional {
+			n.insert(segments[1:], rt)
+		}
+
+	case config.SegmentWildcard:
+		for _, w := range n.wildcards {
+			if w.name == segment.Value {
+				w.routes = appendRoute(w.routes, rt)
+				return
+			}
+		}
+		n.wildcards = append(n.wildcards, &wildcardEdge{name: segment.Value, routes: []*route{rt}})
+	}
+}
+
+// param returns the child for a parameter segment, adding it when needed.
+// Constrained parameters are kept ahead of unconstrained ones.
+func (n *node) param(segment config.PathSegment) *node {
+	for _, p := range n.params {
+		if p.name == segment.Value && patternString(p.pattern) == patternString(segment.Pattern) {
+			return p.node
+		}
+	}
+
+	edge := &paramEdge{name: segment.Value, pattern: segment.Pattern, node: &node{}}
+	if segment.Pattern == nil {
+		n.params = append(n.params, edge)
+		return edge.node
+	}
+
+	at := 0
+	for at < len(n.params) && n.params[at].pattern != nil {
+		at++
+	}
+	n.params = append(n.params[:at], append([]*paramEdge{edge}, n.params[at:]...)...)
+	return edge.node
+}
+
+// patternString returns the source of a parameter constraint, empty when there is none
+func patternString(pattern *regexp.Regexp) string {
+	if pattern == nil {
+		return ""
+	}
+	return pattern.String()
+}
+
+// appendRoute adds rt unless it was already registered through another optional expansion
+func appendRoute(routes []*route, rt *route) []*route {
+	if len(routes) > 0 && routes[len(routes)-1] == rt {
+		return routes
+	}
+	return append(routes, rt)
+}
+
+// lookup walks the tree along the path segments and calls visit for every
+// route ending there, most specific first, until visit accepts one
+func (n *node) lookup(parts []string, values []paramValue, visit func(*route, []paramValue) bool) bool {
+	if len(parts) == 0 {
+		for _, rt := range n.routes {
+			if visit(rt, values) {
+				return true
+			}
+		}
+		// Catch-alls also match an empty rest
+		return n.lookupWildcards("", values, visit)
+	}
+
+	part := parts[0]
+	if child, ok := n.static[part]; ok && child.lookup(parts[1:], values, visit) {
+		return true
+	}
+	if part != "" {
+		for _, p := range n.params {
+			if p.pattern != nil && !p.pattern.MatchString(part) {
+				continue
+			}
+			if p.node.lookup(parts[1:], append(values, paramValue{p.name, part}), visit) {
+				return true
+			}
+		}
+	}
+	return n.lookupWildcards(strings.Join(parts, "/"), values, visit)
+}
+
+// lookupWildcards offers the routes ending in a catch-all that captures rest
+func (n *node) lookupWildcards(rest string, values []paramValue, visit func(*route, []paramValue) bool) bool {
+	for _, w := range n.wildcards {
+		captured := append(values, paramValue{w.name, rest})
+		for _, rt := range w.routes {
+			if visit(rt, captured) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Generation returns the configuration generation the router was built from
+func (r *Router) Generation() uint64 {
+	return r.generation
+}
+
+// forHost selects the host groups serving a Host header value: those of the
+// most specific matching virtual host, or the endpoints without hosts when no
+// virtual host matches
+func (r *Router) forHost(host string) []bool {
+	host = config.NormalizeHost(host)
+
+	selected := make([]bool, len(r.groups))
+	best, matched := 0, false
+	for i, patterns := range r.groups[1:] {
+		score, ok := config.MatchHosts(patterns, host)
+		if !ok || (matched && score < best) {
+			continue
+		}
+		if !matched || score > best {
+			clear(selected)
+			best, matched = score, true
+		}
+		selected[i+1] = true
+	}
+	if !matched {
+		selected[0] = true
+	}
+	return selected
+}
+
+// matchFolder returns the first static file endpoint of the selected groups
+// whose path prefixes the request path and that accept agrees to serve
+func (r *Router) matchFolder(groups []bool, path string, accept func(*route) bool) *route {
+	for _, rt := range r.folders {
+		if groups[rt.group] && strings.HasPrefix(path, rt.endpoint.Path) && accept(rt) {
+			return rt
+		}
+	}
+	return nil
+}
+
+// match returns the most specific API endpoint of the selected groups that
+// matches the path and that accept agrees to serve, with its path parameters
+func (r *Router) match(groups []bool, path string, accept func(*route) bool) (*route, map[string]string) {
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+
+	var matched *route
+	var params map[string]string
+	visit := func(rt *route, values []paramValue) bool {
+		if !accept(rt) {
+			return false
+		}
+		matched = rt
+		if len(values) > 0 || len(rt.optional) > 0 {
+			params = make(map[string]string, len(values)+len(rt.optional))
+			for _, name := range rt.optional {
+				params[name] = ""
+			}
+			for _, v := range values {
+				params[v.name] = v.value
+			}
+		}
+		return true
+	}
+
+	for group, tree := range r.trees {
+		if groups[group] && tree.lookup(parts, make([]paramValue, 0, 4), visit) {
+			return matched, params
+		}
+	}
+	return nil, nil
+}
+
+// allowedMethods returns the sorted methods of the API endpoints of the
+// selected groups that match the path
+func (r *Router) allowedMethods(groups []bool, path string) []string {
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+
+	seen := make(map[string]bool)
+	visit := func(rt *route, _ []paramValue) bool {
+		seen[rt.endpoint.Method] = true
+		// Keep walking to collect every matching endpoint
+		return false
+	}
+	for group, tree := range r.trees {
+		if groups[group] {
+			tree.lookup(parts, make([]paramValue, 0, 4), visit)
+		}
+	}
+
+	methods := make([]string, 0, len(seen))
+	for method := range seen {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
@@ -0,0 +1,284 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tkc/go-json-server/src/config"
+	"github.com/tkc/go-json-server/src/logger"
+)
+
+// matchAny returns the endpoint path matching a GET request and its parameters
+func matchAny(router *Router, host, path string) (string, map[string]string) {
+	rt, params := router.match(router.forHost(host), path, func(rt *route) bool {
+		return rt.endpoint.Method == http.MethodGet
+	})
+	if rt == nil {
+		return "", nil
+	}
+	return rt.endpoint.Path, params
+}
+
+func TestRouter_Match(t *testing.T) {
+	router := NewRouter([]config.Endpoint{
+		{Method: "GET", Path: "/"},
+		{Method: "GET", Path: "/users"},
+		{Method: "GET", Path: "/users/:name"},
+		{Method: "GET", Path: `/users/:id(\d+)`},
+		{Method: "GET", Path: "/users/me"},
+		{Method: "GET", Path: "/users/:id/posts/:postId?"},
+		{Method: "GET", Path: "/users/:id/avatar"},
+		{Method: "GET", Path: "/files/*rest"},
+		{Method: "GET", Path: "/files/readme"},
+		{Method: "GET", Path: `/archive/:year(\d{4})/*rest`},
+		{Method: "GET", Path: "/disabled", Disabled: true},
+		{Method: "POST", Path: "/orders"},
+		{Method: "GET", Path: "/static/", Folder: "./public"},
+	})
+
+	tests := []struct {
+		path    string
+		pattern string
+		params  map[string]string
+	}{
+		{"/", "/", nil},
+		{"/users", "/users", nil},
+
+		// Static beats constrained parameters, which beat plain parameters
+		{"/users/me", "/users/me", nil},
+		{"/users/42", `/users/:id(\d+)`, map[string]string{"id": "42"}},
+		{"/users/bob", "/users/:name", map[string]string{"name": "bob"}},
+		{"/users/", "", nil},
+
+		// Optional parameters may be left out
+		{"/users/1/posts/2", "/users/:id/posts/:postId?", map[string]string{"id": "1", "postId": "2"}},
+		{"/users/1/posts", "/users/:id/posts/:postId?", map[string]string{"id": "1", "postId": ""}},
+		{"/users/1/avatar", "/users/:id/avatar", map[string]string{"id": "1"}},
+		{"/users/1/likes", "", nil},
+
+		// Catch-alls take the rest of the path, static siblings win
+		{"/files/readme", "/files/readme", nil},
+		{"/files/docs/guide.md", "/files/*rest", map[string]string{"rest": "docs/guide.md"}},
+		{"/files/", "/files/*rest", map[string]string{"rest": ""}},
+		{"/files", "/files/*rest", map[string]string{"rest": ""}},
+		{"/archive/2024/01/report", `/archive/:year(\d{4})/*rest`, map[string]string{"year": "2024", "rest": "01/report"}},
+		{"/archive/24/01", "", nil},
+
+		{"/disabled", "", nil},
+		{"/orders", "", nil},
+		{"/static/app.js", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			pattern, params := matchAny(router, "", tt.path)
+			assert.Equal(t, tt.pattern, pattern)
+			assert.Equal(t, tt.params, params)
+		})
+	}
+}
+
+func TestRouter_MatchFallsBack(t *testing.T) {
+	router := NewRouter([]config.Endpoint{
+		{Method: "GET", Path: "/items/special"},
+		{Method: "POST", Path: "/items/:id"},
+		{Method: "GET", Path: "/items/*rest"},
+	})
+
+	// A more specific path that the request cannot use does not hide less specific ones
+	rt, params := router.match(router.forHost(""), "/items/special", func(rt *route) bool {
+		return rt.endpoint.Method == http.MethodPost
+	})
+	assert.Equal(t, "/items/:id", rt.endpoint.Path)
+	assert.Equal(t, map[string]string{"id": "special"}, params)
+
+	rt, params = router.match(router.forHost(""), "/items/special", func(rt *route) bool {
+		return rt.endpoint.Path != "/items/special"
+	})
+	assert.Equal(t, "/items/:id", rt.endpoint.Path)
+	assert.Equal(t, map[string]string{"id": "special"}, params)
+}
+
+func TestRouter_ForHost(t *testing.T) {
+	router := NewRouter([]config.Endpoint{
+		{Method: "GET", Path: "/a"},
+		{Method: "GET", Path: "/b", Hosts: []string{"*.api.local"}},
+		{Method: "GET", Path: "/c", Hosts: []string{"users.api.local"}},
+		{Method: "GET", Path: "/d", Hosts: []string{"*.api.local"}},
+		{Method: "GET", Path: "/e", Hosts: []string{"*.eu.api.local"}},
+	})
+
+	pattern, _ := matchAny(router, "localhost:3000", "/a")
+	assert.Equal(t, "/a", pattern)
+	pattern, _ = matchAny(router, "localhost:3000", "/b")
+	assert.Empty(t, pattern)
+
+	pattern, _ = matchAny(router, "posts.api.local", "/d")
+	assert.Equal(t, "/d", pattern)
+	pattern, _ = matchAny(router, "posts.api.local", "/a")
+	assert.Empty(t, pattern)
+
+	pattern, _ = matchAny(router, "USERS.api.local:8080", "/c")
+	assert.Equal(t, "/c", pattern)
+	pattern, _ = matchAny(router, "users.api.local", "/b")
+	assert.Empty(t, pattern)
+
+	// The longest wildcard wins and does not match the bare domain
+	pattern, _ = matchAny(router, "orders.eu.api.local", "/e")
+	assert.Equal(t, "/e", pattern)
+	pattern, _ = matchAny(router, "orders.eu.api.local", "/d")
+	assert.Empty(t, pattern)
+	pattern, _ = matchAny(router, "api.local", "/a")
+	assert.Equal(t, "/a", pattern)
+}
+
+func TestRouter_GroupsHostsRegardlessOfOrderAndCase(t *testing.T) {
+	router := NewRouter([]config.Endpoint{
+		{Method: "GET", Path: "/a", Hosts: []string{"users.api.local", "*.users.local"}},
+		{Method: "GET", Path: "/b", Hosts: []string{"*.USERS.local", "Users.API.local"}},
+	})
+	assert.Len(t, router.groups, 2)
+
+	// Both endpoints are served together for either host
+	for _, host := range []string{"users.api.local", "eu.users.local"} {
+		pattern, _ := matchAny(router, host, "/a")
+		assert.Equal(t, "/a", pattern)
+		pattern, _ = matchAny(router, host, "/b")
+		assert.Equal(t, "/b", pattern)
+	}
+}
+
+func TestServer_RebuildOnChange(t *testing.T) {
+	tempDir := t.TempDir()
+	jsonFile := filepath.Join(tempDir, "order.json")
+	assert.NoError(t, os.WriteFile(jsonFile, []byte(`{"id": ":id"}`), 0644))
+
+	cfg := &config.Config{Endpoints: []config.Endpoint{{Method: "GET", Status: 200, Path: "/users", JsonPath: jsonFile}}}
+	log, err := logger.NewLogger(logger.LogConfig{Level: logger.LevelError})
+	assert.NoError(t, err)
+	server := NewServer(cfg, log, time.Minute)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		server.HandleRequest(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+	assert.Equal(t, http.StatusNotFound, get("/orders/7").Code)
+
+	// A request that started before the change keeps its router
+	old := server.Router()
+
+	// Endpoints with parameters added at runtime match right away
+	_, err = cfg.AddEndpoint(config.Endpoint{Method: "GET", Status: 200, Path: "/orders/:id", JsonPath: jsonFile})
+	assert.NoError(t, err)
+
+	rec := get("/orders/7")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"id": "7"}`, rec.Body.String())
+
+	current := server.Router()
+	assert.NotSame(t, old, current)
+	assert.Greater(t, current.Generation(), old.Generation())
+	pattern, _ := matchAny(old, "", "/orders/7")
+	assert.Empty(t, pattern)
+	pattern, _ = matchAny(current, "", "/orders/7")
+	assert.Equal(t, "/orders/:id", pattern)
+
+	// Rebuilding without changes keeps the current router
+	assert.Same(t, current, server.Rebuild())
+}
+
+// linearMatch is the matcher the router replaced: every request scans all
+// endpoints and splits the pattern and path again for each of them
+func linearMatch(endpoints []config.Endpoint, method, path string) (*config.Endpoint, map[string]string) {
+	for i, ep := range endpoints {
+		if ep.Method != method {
+			continue
+		}
+		if ep.Path == path {
+			return &endpoints[i], nil
+		}
+		if !strings.Contains(ep.Path, ":") {
+			continue
+		}
+
+		patternParts := strings.Split(ep.Path, "/")
+		pathParts := strings.Split(path, "/")
+		if len(patternParts) != len(pathParts) {
+			continue
+		}
+		params := make(map[string]string)
+		matched := true
+		for j, part := range patternParts {
+			if strings.HasPrefix(part, ":") {
+				params[part[1:]] = pathParts[j]
+			} else if part != pathParts[j] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return &endpoints[i], params
+		}
+	}
+	return nil, nil
+}
+
+// benchmarkEndpoints returns hundreds of endpoints shaped like a REST API
+func benchmarkEndpoints() []config.Endpoint {
+	var endpoints []config.Endpoint
+	for i := 0; i < 100; i++ {
+		resource := "/resources" + strconv.Itoa(i)
+		endpoints = append(endpoints,
+			config.Endpoint{Method: "GET", Path: resource},
+			config.Endpoint{Method: "POST", Path: resource},
+			config.Endpoint{Method: "GET", Path: resource + "/:id"},
+			config.Endpoint{Method: "PUT", Path: resource + "/:id"},
+			config.Endpoint{Method: "GET", Path: resource + "/:id/items/:itemId"},
+		)
+	}
+	return endpoints
+}
+
+// benchmarkPaths are requests hitting the start, middle and end of the endpoint list
+var benchmarkPaths = []string{
+	"/resources0",
+	"/resources50/7",
+	"/resources99/7/items/3",
+	"/missing/path",
+}
+
+func BenchmarkRouter(b *testing.B) {
+	router := NewRouter(benchmarkEndpoints())
+	groups := router.forHost("")
+	accept := func(rt *route) bool { return rt.endpoint.Method == http.MethodGet }
+
+	for _, path := range benchmarkPaths {
+		b.Run(path, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				router.match(groups, path, accept)
+			}
+		})
+	}
+}
+
+func BenchmarkLinearMatch(b *testing.B) {
+	endpoints := benchmarkEndpoints()
+
+	for _, path := range benchmarkPaths {
+		b.Run(path, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				linearMatch(endpoints, http.MethodGet, path)
+			}
+		})
+	}
+}
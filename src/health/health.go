@@ -0,0 +1,117 @@
+package health
+
+import (
+	"net/http"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/tkc/go-json-server/src/config"
+	"github.com/tkc/go-json-server/src/internal/httputil"
+)
+
+// Reserved paths of the probe endpoints
+const (
+	HealthPath = "/__health"
+	ReadyPath  = "/__ready"
+	InfoPath   = "/__info"
+)
+
+// Paths lists all reserved probe paths
+var Paths = []string{HealthPath, ReadyPath, InfoPath}
+
+// Checker serves liveness, readiness and build information, independent of
+// the user-defined endpoints
+type Checker struct {
+	Config     *config.Config
+	Version    string
+	ConfigPath string
+
+	started time.Time
+	now     func() time.Time
+
+	mu     sync.RWMutex
+	ready  bool
+	reason string
+	mux    *http.ServeMux
+}
+
+// New creates a checker that is not ready until SetReady is called
+func New(cfg *config.Config, version, configPath string) *Checker {
+	c := &Checker{
+		Config:     cfg,
+		Version:    version,
+		ConfigPath: configPath,
+		started:    time.Now(),
+		now:        time.Now,
+		reason:     "starting",
+		mux:        http.NewServeMux(),
+	}
+
+	c.mux.HandleFunc(HealthPath, c.health)
+	c.mux.HandleFunc(ReadyPath, c.readiness)
+	c.mux.HandleFunc(InfoPath, c.info)
+
+	return c
+}
+
+// SetReady marks the server ready, or not ready for the given reason
+func (c *Checker) SetReady(ready bool, reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ready = ready
+	c.reason = reason
+	if ready {
+		c.reason = ""
+	}
+}
+
+// Ready reports whether the server is ready and why not
+func (c *Checker) Ready() (bool, string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.ready, c.reason
+}
+
+// ServeHTTP routes requests to the probe endpoints
+func (c *Checker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mux.ServeHTTP(w, r)
+}
+
+// health answers the liveness probe: the process is up and serving
+func (c *Checker) health(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// readiness answers the readiness probe
+func (c *Checker) readiness(w http.ResponseWriter, r *http.Request) {
+	ready, reason := c.Ready()
+	if !ready {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "not ready", "reason": reason})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ready"})
+}
+
+// info describes the running server
+func (c *Checker) info(w http.ResponseWriter, r *http.Request) {
+	ready, _ := c.Ready()
+	uptime := c.now().Sub(c.started).Truncate(time.Second)
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"version":       c.Version,
+		"goVersion":     runtime.Version(),
+		"startedAt":     c.started.Format(time.RFC3339),
+		"uptime":        uptime.String(),
+		"uptimeSeconds": int64(uptime.Seconds()),
+		"configPath":    c.ConfigPath,
+		"endpoints":     len(c.Config.GetEndpoints()),
+		"ready":         ready,
+	})
+}
+
+// writeJSON writes a JSON response with the given status that is never cached
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Cache-Control", "no-store")
+	httputil.WriteJSON(w, status, body)
+}
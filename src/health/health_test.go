@@ -0,0 +1,62 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tkc/go-json-server/src/config"
+)
+
+func TestChecker(t *testing.T) {
+	cfg := &config.Config{
+		Endpoints: []config.Endpoint{
+			{Method: "GET", Path: "/users"},
+			{Method: "GET", Path: "/posts"},
+		},
+	}
+	checker := New(cfg, "1.2.3", "./api.json")
+
+	get := func(path string) (*httptest.ResponseRecorder, map[string]any) {
+		w := httptest.NewRecorder()
+		checker.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		var body map[string]any
+		json.Unmarshal(w.Body.Bytes(), &body)
+		return w, body
+	}
+
+	// Liveness does not depend on readiness
+	w, body := get(HealthPath)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "ok", body["status"])
+
+	// Not ready until startup completes
+	w, body = get(ReadyPath)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "starting", body["reason"])
+
+	checker.SetReady(true, "")
+	w, body = get(ReadyPath)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "ready", body["status"])
+
+	// A failing reload makes the server not ready
+	checker.SetReady(false, "configuration reload failed")
+	w, body = get(ReadyPath)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "configuration reload failed", body["reason"])
+
+	// Info describes the running server
+	checker.now = func() time.Time { return checker.started.Add(90 * time.Second) }
+	w, body = get(InfoPath)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1.2.3", body["version"])
+	assert.Equal(t, "./api.json", body["configPath"])
+	assert.Equal(t, float64(2), body["endpoints"])
+	assert.Equal(t, "1m30s", body["uptime"])
+	assert.Equal(t, float64(90), body["uptimeSeconds"])
+	assert.Equal(t, false, body["ready"])
+}
@@ -0,0 +1,21 @@
+// Package httputil holds the response and credential helpers shared by the
+// built-in HTTP services.
+package httputil
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+)
+
+// WriteJSON writes body as a JSON response with the given status
+func WriteJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// SecureCompare compares secrets in constant time
+func SecureCompare(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
@@ -0,0 +1,25 @@
+package httputil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteJSON(t *testing.T) {
+	w := httptest.NewRecorder()
+	WriteJSON(w, http.StatusCreated, map[string]string{"id": "1"})
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "application/json; charset=UTF-8", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"id": "1"}`, w.Body.String())
+}
+
+func TestSecureCompare(t *testing.T) {
+	assert.True(t, SecureCompare("secret", "secret"))
+	assert.False(t, SecureCompare("secret", "Secret"))
+	assert.False(t, SecureCompare("secret", "secret2"))
+	assert.False(t, SecureCompare("", "secret"))
+}
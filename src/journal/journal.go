@@ -0,0 +1,290 @@
+package journal
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCapacity is the number of requests kept when no capacity is configured
+const DefaultCapacity = 1000
+
+// Redacted replaces the values of redacted headers
+const Redacted = "[REDACTED]"
+
+// DefaultRedactedHeaders are the credential headers whose values are not recorded
+var DefaultRedactedHeaders = []string{"Authorization", "Cookie", "Proxy-Authorization"}
+
+// Entry represents a recorded request and the response it received
+type Entry struct {
+	ID            int64       `json:"id"`
+	RequestID     string      `json:"requestId,omitempty"`
+	Time          time.Time   `json:"time"`
+	RemoteAddr    string      `json:"remoteAddr"`
+	Method        string      `json:"method"`
+	Path          string      `json:"path"`
+	Query         string      `json:"query,omitempty"`
+	Headers       http.Header `json:"headers"`
+	Body          string      `json:"body,omitempty"`
+	BodyTruncated bool        `json:"bodyTruncated,omitempty"`
+	EndpointID    string      `json:"endpointId,omitempty"`
+	Endpoint      string      `json:"endpoint,omitempty"`
+	Status        int         `json:"status"`
+	Latency       float64     `json:"latency_ms"`
+}
+
+// Journal is a bounded in-memory record of requests.
+// Once full, the oldest entries are overwritten.
+type Journal struct {
+	// RedactHeaders are the headers whose values are replaced before recording
+	RedactHeaders []string
+
+	mu      sync.RWMutex
+	entries []Entry
+	next    int
+	full    bool
+	seq     int64
+}
+
+// New creates a journal holding up to capacity entries
+func New(capacity int) *Journal {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+	return &Journal{
+		RedactHeaders: DefaultRedactedHeaders,
+		entries:       make([]Entry, capacity),
+	}
+}
+
+// Record adds an entry to the journal, assigning it a sequence ID and
+// redacting credential headers
+func (j *Journal) Record(entry Entry) {
+	entry.Headers = redact(entry.Headers, j.RedactHeaders)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.seq++
+	entry.ID = j.seq
+	j.entries[j.next] = entry
+	j.next = (j.next + 1) % len(j.entries)
+	if j.next == 0 {
+		j.full = true
+	}
+}
+
+// redact returns a copy of header with the values of the given headers replaced
+func redact(header http.Header, names []string) http.Header {
+	var redacted http.Header
+	for _, name := range names {
+		values := header.Values(name)
+		if len(values) == 0 {
+			continue
+		}
+		if redacted == nil {
+			redacted = header.Clone()
+		}
+		replaced := make([]string, len(values))
+		for i := range replaced {
+			replaced[i] = Redacted
+		}
+		redacted[http.CanonicalHeaderKey(name)] = replaced
+	}
+	if redacted == nil {
+		return header
+	}
+	return redacted
+}
+
+// Entries returns the entries matching the filter, oldest first
+func (j *Journal) Entries(filter Filter) []Entry {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	result := make([]Entry, 0)
+	for _, entry := range j.ordered() {
+		if filter.Match(entry) {
+			result = append(result, entry)
+		}
+	}
+
+	// Keep the most recent entries when a limit is set
+	if filter.Limit > 0 && len(result) > filter.Limit {
+		result = result[len(result)-filter.Limit:]
+	}
+	return result
+}
+
+// Clear removes all entries
+func (j *Journal) Clear() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.entries = make([]Entry, len(j.entries))
+	j.next = 0
+	j.full = false
+}
+
+// ordered returns the stored entries from oldest to newest; callers must hold the lock
+func (j *Journal) ordered() []Entry {
+	if !j.full {
+		return j.entries[:j.next]
+	}
+	ordered := make([]Entry, 0, len(j.entries))
+	ordered = append(ordered, j.entries[j.next:]...)
+	return append(ordered, j.entries[:j.next]...)
+}
+
+// Filter selects journal entries. Empty fields match everything.
+type Filter struct {
+	Method       string            `json:"method"`
+	Path         string            `json:"path"`
+	PathRegex    string            `json:"pathRegex"`
+	EndpointID   string            `json:"endpointId"`
+	Endpoint     string            `json:"endpoint"`
+	Status       int               `json:"status"`
+	Since        time.Time         `json:"since"`
+	Headers      map[string]string `json:"headers"`
+	BodyContains string            `json:"bodyContains"`
+	BodyRegex    string            `json:"bodyRegex"`
+	BodyJSON     json.RawMessage   `json:"bodyJSON"`
+	AfterID      int64             `json:"afterId"`
+	Limit        int               `json:"limit"`
+
+	pathRegexp *regexp.Regexp
+	bodyRegexp *regexp.Regexp
+	bodyJSON   any
+}
+
+// Compile prepares the filter's patterns and must be called before Match
+func (f *Filter) Compile() error {
+	var err error
+	if f.PathRegex != "" {
+		if f.pathRegexp, err = regexp.Compile(f.PathRegex); err != nil {
+			return err
+		}
+	}
+	if f.BodyRegex != "" {
+		if f.bodyRegexp, err = regexp.Compile(f.BodyRegex); err != nil {
+			return err
+		}
+	}
+	if len(f.BodyJSON) > 0 {
+		if err = json.Unmarshal(f.BodyJSON, &f.bodyJSON); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Match reports whether an entry satisfies the filter
+func (f Filter) Match(entry Entry) bool {
+	if f.AfterID != 0 && entry.ID <= f.AfterID {
+		return false
+	}
+	if f.Method != "" && !strings.EqualFold(f.Method, entry.Method) {
+		return false
+	}
+	if f.Path != "" && f.Path != entry.Path {
+		return false
+	}
+	if f.pathRegexp != nil && !f.pathRegexp.MatchString(entry.Path) {
+		return false
+	}
+	if f.EndpointID != "" && f.EndpointID != entry.EndpointID {
+		return false
+	}
+	if f.Endpoint != "" && f.Endpoint != entry.Endpoint {
+		return false
+	}
+	if f.Status != 0 && f.Status != entry.Status {
+		return false
+	}
+	if !f.Since.IsZero() && entry.Time.Before(f.Since) {
+		return false
+	}
+	for name, value := range f.Headers {
+		if entry.Headers.Get(name) != value {
+			return false
+		}
+	}
+	if f.BodyContains != "" && !strings.Contains(entry.Body, f.BodyContains) {
+		return false
+	}
+	if f.bodyRegexp != nil && !f.bodyRegexp.MatchString(entry.Body) {
+		return false
+	}
+	if f.bodyJSON != nil {
+		var actual any
+		if err := json.Unmarshal([]byte(entry.Body), &actual); err != nil || !containsJSON(actual, f.bodyJSON) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsJSON reports whether actual contains expected: objects may have extra
+// fields, while arrays and scalar values must be equal
+func containsJSON(actual, expected any) bool {
+	expectedObject, ok := expected.(map[string]any)
+	if !ok {
+		return reflect.DeepEqual(actual, expected)
+	}
+
+	actualObject, ok := actual.(map[string]any)
+	if !ok {
+		return false
+	}
+	for key, value := range expectedObject {
+		actualValue, exists := actualObject[key]
+		if !exists || !containsJSON(actualValue, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// Verification describes an expectation about recorded requests.
+// Without any count constraint, at least one matching request is expected.
+type Verification struct {
+	Filter
+	Count   *int `json:"count"`
+	AtLeast *int `json:"atLeast"`
+	AtMost  *int `json:"atMost"`
+}
+
+// VerificationResult is the outcome of a verification
+type VerificationResult struct {
+	Verified bool    `json:"verified"`
+	Count    int     `json:"count"`
+	Requests []Entry `json:"requests"`
+}
+
+// Verify checks the expectation against the journal
+func (j *Journal) Verify(v Verification) VerificationResult {
+	filter := v.Filter
+	filter.Limit = 0
+	matches := j.Entries(filter)
+	count := len(matches)
+
+	verified := true
+	if v.Count != nil && count != *v.Count {
+		verified = false
+	}
+	if v.AtLeast != nil && count < *v.AtLeast {
+		verified = false
+	}
+	if v.AtMost != nil && count > *v.AtMost {
+		verified = false
+	}
+	if v.Count == nil && v.AtLeast == nil && v.AtMost == nil && count == 0 {
+		verified = false
+	}
+
+	return VerificationResult{Verified: verified, Count: count, Requests: matches}
+}
@@ -0,0 +1,128 @@
+package journal
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func intPtr(v int) *int { return &v }
+
+func TestJournal_RecordAndBound(t *testing.T) {
+	j := New(3)
+
+	for _, path := range []string{"/a", "/b", "/c", "/d"} {
+		j.Record(Entry{Method: "GET", Path: path, Status: 200})
+	}
+
+	entries := j.Entries(Filter{})
+	assert.Len(t, entries, 3)
+	assert.Equal(t, "/b", entries[0].Path)
+	assert.Equal(t, "/d", entries[2].Path)
+	assert.Equal(t, int64(4), entries[2].ID)
+
+	// Limit keeps the most recent entries
+	entries = j.Entries(Filter{Limit: 1})
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "/d", entries[0].Path)
+
+	j.Clear()
+	assert.Empty(t, j.Entries(Filter{}))
+}
+
+func TestJournal_RedactsCredentials(t *testing.T) {
+	j := New(10)
+	header := http.Header{
+		"Authorization": {"Bearer secret"},
+		"Cookie":        {"session=abc"},
+		"Accept":        {"application/json"},
+	}
+	j.Record(Entry{Method: "GET", Path: "/users", Headers: header})
+
+	recorded := j.Entries(Filter{})[0].Headers
+	assert.Equal(t, Redacted, recorded.Get("Authorization"))
+	assert.Equal(t, Redacted, recorded.Get("Cookie"))
+	assert.Equal(t, "application/json", recorded.Get("Accept"))
+
+	// The request's own headers are left alone
+	assert.Equal(t, "Bearer secret", header.Get("Authorization"))
+
+	// Redaction can be turned off
+	j.RedactHeaders = nil
+	j.Record(Entry{Method: "GET", Path: "/users", Headers: header})
+	assert.Equal(t, "Bearer secret", j.Entries(Filter{Limit: 1})[0].Headers.Get("Authorization"))
+}
+
+func TestFilter_Match(t *testing.T) {
+	entry := Entry{
+		Time:       time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		Method:     "POST",
+		Path:       "/users",
+		Headers:    http.Header{"Content-Type": {"application/json"}},
+		Body:       `{"name":"alice","role":{"id":1,"name":"admin"},"tags":["a"]}`,
+		EndpointID: "create-user",
+		Endpoint:   "/users",
+		Status:     201,
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{"Empty", Filter{}, true},
+		{"Method is case insensitive", Filter{Method: "post"}, true},
+		{"Other method", Filter{Method: "GET"}, false},
+		{"Path", Filter{Path: "/users"}, true},
+		{"Path regex", Filter{PathRegex: "^/us"}, true},
+		{"Path regex mismatch", Filter{PathRegex: "^/posts"}, false},
+		{"Endpoint ID", Filter{EndpointID: "create-user"}, true},
+		{"Status", Filter{Status: 200}, false},
+		{"Since", Filter{Since: entry.Time.Add(time.Minute)}, false},
+		{"Header", Filter{Headers: map[string]string{"content-type": "application/json"}}, true},
+		{"Body contains", Filter{BodyContains: "alice"}, true},
+		{"Body regex", Filter{BodyRegex: `"name":"bob"`}, false},
+		{"Body JSON subset", Filter{BodyJSON: json.RawMessage(`{"role":{"name":"admin"}}`)}, true},
+		{"Body JSON mismatch", Filter{BodyJSON: json.RawMessage(`{"name":"bob"}`)}, false},
+		{"Body JSON arrays must be equal", Filter{BodyJSON: json.RawMessage(`{"tags":["a","b"]}`)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := tt.filter
+			assert.NoError(t, filter.Compile())
+			assert.Equal(t, tt.want, filter.Match(entry))
+		})
+	}
+
+	invalid := Filter{PathRegex: "("}
+	assert.Error(t, invalid.Compile())
+}
+
+func TestJournal_Verify(t *testing.T) {
+	j := New(10)
+	j.Record(Entry{Method: "POST", Path: "/users", Body: `{"name":"alice"}`})
+	j.Record(Entry{Method: "POST", Path: "/users", Body: `{"name":"bob"}`})
+	j.Record(Entry{Method: "GET", Path: "/users"})
+
+	posts := Filter{Method: "POST", Path: "/users"}
+
+	assert.True(t, j.Verify(Verification{Filter: posts, Count: intPtr(2)}).Verified)
+	assert.False(t, j.Verify(Verification{Filter: posts, Count: intPtr(1)}).Verified)
+	assert.True(t, j.Verify(Verification{Filter: posts, AtLeast: intPtr(1), AtMost: intPtr(2)}).Verified)
+	assert.False(t, j.Verify(Verification{Filter: posts, AtMost: intPtr(1)}).Verified)
+
+	// Without a count, at least one request is expected
+	assert.True(t, j.Verify(Verification{Filter: posts}).Verified)
+	assert.False(t, j.Verify(Verification{Filter: Filter{Method: "DELETE"}}).Verified)
+
+	withBody := Verification{Filter: Filter{Method: "POST", BodyJSON: json.RawMessage(`{"name":"bob"}`)}, Count: intPtr(1)}
+	assert.NoError(t, withBody.Compile())
+	result := j.Verify(withBody)
+	assert.True(t, result.Verified)
+	assert.Equal(t, 1, result.Count)
+	assert.Len(t, result.Requests, 1)
+}
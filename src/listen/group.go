@@ -0,0 +1,142 @@
+package listen
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+)
+
+// Spec describes a listener to open
+type Spec struct {
+	// Address is host:port or unix:/path/to.sock
+	Address string
+	// Mode sets the permissions of a Unix socket when non-zero
+	Mode os.FileMode
+}
+
+// Group serves an http.Server on a set of listeners that can be replaced
+// while it runs. Requests already accepted on a replaced listener are left to
+// finish; only accepting new connections stops.
+type Group struct {
+	Server *http.Server
+
+	// CertFile and KeyFile serve HTTPS when set
+	CertFile string
+	KeyFile  string
+
+	// OnServe is called when a listener starts serving
+	OnServe func(net.Listener)
+	// OnClose is called when a replaced listener has been closed
+	OnClose func(net.Listener)
+
+	errs    chan<- error
+	mu      sync.Mutex
+	active  map[string]net.Listener
+	retired map[net.Listener]bool
+}
+
+// NewGroup creates a group serving server. The error returned by serving a
+// listener is sent to errs unless the listener was replaced by Rebind.
+func NewGroup(server *http.Server, errs chan<- error) *Group {
+	return &Group{
+		Server:  server,
+		errs:    errs,
+		active:  make(map[string]net.Listener),
+		retired: make(map[net.Listener]bool),
+	}
+}
+
+// Serve starts serving on a listener that was opened for address
+func (g *Group) Serve(address string, l net.Listener) {
+	g.mu.Lock()
+	g.active[address] = l
+	g.mu.Unlock()
+
+	go g.serve(l)
+}
+
+// Addresses returns the addresses being served, sorted
+func (g *Group) Addresses() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	addresses := make([]string, 0, len(g.active))
+	for address := range g.active {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	return addresses
+}
+
+// Rebind moves the group to the listeners in specs. Addresses that are
+// already served keep their listener, new ones are opened and start serving
+// before the listeners that are no longer wanted are closed. Nothing changes
+// when one of the new listeners cannot be opened.
+func (g *Group) Rebind(specs []Spec) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	wanted := make(map[string]bool, len(specs))
+	opened := make(map[string]net.Listener)
+	for _, spec := range specs {
+		wanted[spec.Address] = true
+		if _, ok := g.active[spec.Address]; ok {
+			continue
+		}
+		if _, ok := opened[spec.Address]; ok {
+			continue
+		}
+
+		l, err := Listen(spec.Address, spec.Mode)
+		if err != nil {
+			for _, l := range opened {
+				l.Close()
+			}
+			return err
+		}
+		opened[spec.Address] = l
+	}
+
+	for address, l := range opened {
+		g.active[address] = l
+		go g.serve(l)
+	}
+
+	for address, l := range g.active {
+		if wanted[address] {
+			continue
+		}
+		delete(g.active, address)
+		g.retired[l] = true
+		l.Close()
+		if g.OnClose != nil {
+			g.OnClose(l)
+		}
+	}
+	return nil
+}
+
+// serve runs the server on l until the listener is closed
+func (g *Group) serve(l net.Listener) {
+	if g.OnServe != nil {
+		g.OnServe(l)
+	}
+
+	var err error
+	if g.CertFile != "" {
+		err = g.Server.ServeTLS(l, g.CertFile, g.KeyFile)
+	} else {
+		err = g.Server.Serve(l)
+	}
+
+	g.mu.Lock()
+	retired := g.retired[l]
+	delete(g.retired, l)
+	g.mu.Unlock()
+
+	if !retired {
+		g.errs <- err
+	}
+}
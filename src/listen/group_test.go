@@ -0,0 +1,101 @@
+package listen
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// unixClient returns a client that dials the Unix socket at path
+func unixClient(path string) *http.Client {
+	return &http.Client{Transport: &http.Transport{
+		Dial: func(_, _ string) (net.Conn, error) { return net.Dial("unix", path) },
+	}}
+}
+
+func TestGroup_Rebind(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "old.sock")
+	newPath := filepath.Join(dir, "new.sock")
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			close(started)
+			<-release
+		}
+		io.WriteString(w, "ok")
+	})}
+	defer srv.Close()
+
+	errs := make(chan error, 4)
+	group := NewGroup(srv, errs)
+	var closed []string
+	group.OnClose = func(l net.Listener) { closed = append(closed, l.Addr().String()) }
+
+	assert.NoError(t, group.Rebind([]Spec{{Address: UnixPrefix + oldPath}}))
+	assert.Equal(t, []string{UnixPrefix + oldPath}, group.Addresses())
+
+	// A request in flight on the old listener
+	slow := make(chan *http.Response)
+	go func() {
+		resp, err := unixClient(oldPath).Get("http://mock/slow")
+		assert.NoError(t, err)
+		slow <- resp
+	}()
+	<-started
+
+	// Keeping the same address is a no-op
+	assert.NoError(t, group.Rebind([]Spec{{Address: UnixPrefix + oldPath}}))
+	assert.Empty(t, closed)
+
+	assert.NoError(t, group.Rebind([]Spec{{Address: UnixPrefix + newPath}}))
+	assert.Equal(t, []string{UnixPrefix + newPath}, group.Addresses())
+	assert.Equal(t, []string{oldPath}, closed)
+
+	resp, err := unixClient(newPath).Get("http://mock/")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	_, err = unixClient(oldPath).Get("http://mock/")
+	assert.Error(t, err)
+
+	// The request accepted before the move still completes
+	close(release)
+	resp = <-slow
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "ok", string(body))
+
+	// Closing a replaced listener is not reported as a server error
+	assert.Empty(t, errs)
+}
+
+func TestGroup_RebindFailure(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "mock.sock")
+
+	srv := &http.Server{Handler: http.NotFoundHandler()}
+	defer srv.Close()
+
+	group := NewGroup(srv, make(chan error, 2))
+	assert.NoError(t, group.Rebind([]Spec{{Address: UnixPrefix + path}}))
+
+	// The current listener is kept when a new one cannot be opened
+	err := group.Rebind([]Spec{
+		{Address: UnixPrefix + filepath.Join(dir, "other.sock")},
+		{Address: UnixPrefix + filepath.Join(dir, "missing", "mock.sock")},
+	})
+	assert.Error(t, err)
+	assert.Equal(t, []string{UnixPrefix + path}, group.Addresses())
+
+	resp, err := unixClient(path).Get("http://mock/")
+	assert.NoError(t, err)
+	resp.Body.Close()
+}
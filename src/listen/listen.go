@@ -0,0 +1,111 @@
+package listen
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// UnixPrefix marks addresses that name a Unix domain socket, e.g. "unix:/run/mock.sock"
+const UnixPrefix = "unix:"
+
+// listenFDsStart is the first file descriptor passed by systemd
+const listenFDsStart = 3
+
+// ErrInvalidAddress is returned for addresses that cannot be listened on
+var ErrInvalidAddress = errors.New("invalid listen address")
+
+// Address builds a TCP listen address from a host and port. An empty host
+// listens on all interfaces.
+func Address(host string, port int) string {
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// ParseAddress splits an address into its network and the address to pass to net.Listen
+func ParseAddress(address string) (network, addr string, err error) {
+	if path, ok := strings.CutPrefix(address, UnixPrefix); ok {
+		if path == "" {
+			return "", "", fmt.Errorf("%w: empty socket path", ErrInvalidAddress)
+		}
+		return "unix", path, nil
+	}
+
+	if _, port, err := net.SplitHostPort(address); err != nil || port == "" {
+		return "", "", fmt.Errorf("%w: %q must be host:port or %s/path", ErrInvalidAddress, address, UnixPrefix)
+	}
+	return "tcp", address, nil
+}
+
+// Listen opens a listener for a host:port or unix:/path address. Unix
+// sockets replace a stale socket file and get the given permissions when mode is non-zero.
+func Listen(address string, mode os.FileMode) (net.Listener, error) {
+	network, addr, err := ParseAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if network != "unix" {
+		return net.Listen(network, addr)
+	}
+
+	// A socket left behind by a previous run would make the bind fail
+	if info, err := os.Lstat(addr); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(addr); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+
+	l, err := net.Listen(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	if mode != 0 {
+		if err := os.Chmod(addr, mode); err != nil {
+			l.Close()
+			return nil, fmt.Errorf("failed to set socket permissions: %w", err)
+		}
+	}
+	return l, nil
+}
+
+// Systemd returns the sockets passed by systemd socket activation, or nil when
+// the process was not socket activated. The activation environment is cleared
+// so that child processes do not inherit it.
+func Systemd() ([]net.Listener, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count <= 0 {
+		return nil, nil
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	listeners := make([]net.Listener, 0, count)
+	for i := 0; i < count; i++ {
+		name := "LISTEN_FD_" + strconv.Itoa(listenFDsStart+i)
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+
+		file := os.NewFile(uintptr(listenFDsStart+i), name)
+		l, err := net.FileListener(file)
+		file.Close()
+		if err != nil {
+			for _, opened := range listeners {
+				opened.Close()
+			}
+			return nil, fmt.Errorf("failed to use systemd socket %s: %w", name, err)
+		}
+		listeners = append(listeners, l)
+	}
+	return listeners, nil
+}
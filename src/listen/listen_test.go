@@ -0,0 +1,84 @@
+package listen
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddress(t *testing.T) {
+	assert.Equal(t, ":3000", Address("", 3000))
+	assert.Equal(t, "127.0.0.1:3000", Address("127.0.0.1", 3000))
+	assert.Equal(t, "[::1]:3000", Address("::1", 3000))
+}
+
+func TestParseAddress(t *testing.T) {
+	network, addr, err := ParseAddress("127.0.0.1:3000")
+	assert.NoError(t, err)
+	assert.Equal(t, "tcp", network)
+	assert.Equal(t, "127.0.0.1:3000", addr)
+
+	network, addr, err = ParseAddress("unix:/run/mock.sock")
+	assert.NoError(t, err)
+	assert.Equal(t, "unix", network)
+	assert.Equal(t, "/run/mock.sock", addr)
+
+	for _, address := range []string{"", "localhost", "localhost:", "unix:"} {
+		_, _, err := ParseAddress(address)
+		assert.ErrorIs(t, err, ErrInvalidAddress, address)
+	}
+}
+
+func TestListen_Unix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mock.sock")
+
+	l, err := Listen(UnixPrefix+path, 0600)
+	assert.NoError(t, err)
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	go http.Serve(l, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	client := &http.Client{Transport: &http.Transport{
+		Dial: func(_, _ string) (net.Conn, error) { return net.Dial("unix", path) },
+	}}
+	resp, err := client.Get("http://mock/")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	// Listening again replaces the stale socket file
+	l.Close()
+	stale, err := net.Listen("unix", path)
+	assert.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	l, err = Listen(UnixPrefix+path, 0)
+	assert.NoError(t, err)
+	l.Close()
+}
+
+func TestSystemd_NotActivated(t *testing.T) {
+	t.Setenv("LISTEN_FDS", "1")
+
+	// Sockets meant for another process are ignored
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()+1))
+	listeners, err := Systemd()
+	assert.NoError(t, err)
+	assert.Nil(t, listeners)
+
+	t.Setenv("LISTEN_PID", "")
+	listeners, err = Systemd()
+	assert.NoError(t, err)
+	assert.Nil(t, listeners)
+}
@@ -2,6 +2,7 @@ package logger
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,7 +10,10 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/tkc/go-json-server/src/tracing"
 )
 
 // LogLevel represents logging levels
@@ -71,10 +75,15 @@ const (
 
 // Logger represents a logger instance
 type Logger struct {
+	mu         sync.Mutex
 	level      LogLevel
 	format     LogFormat
 	writer     io.Writer
 	timeFormat string
+
+	// outputPath and file track the log file opened by the logger itself
+	outputPath string
+	file       *os.File
 }
 
 // LogConfig holds logger configuration
@@ -91,6 +100,8 @@ type LogEntry struct {
 	Level   string         `json:"level"`
 	Message string         `json:"message"`
 	Data    map[string]any `json:"data,omitempty"`
+	TraceID string         `json:"trace_id,omitempty"`
+	SpanID  string         `json:"span_id,omitempty"`
 }
 
 // AccessLogEntry represents an HTTP access log entry
@@ -104,30 +115,26 @@ type AccessLogEntry struct {
 	UserAgent  string         `json:"user_agent"`
 	Latency    float64        `json:"latency_ms"`
 	Body       map[string]any `json:"body,omitempty"`
+	TraceID    string         `json:"trace_id,omitempty"`
+	SpanID     string         `json:"span_id,omitempty"`
 }
 
 // NewLogger creates a new logger instance
 func NewLogger(config LogConfig) (*Logger, error) {
-	var writer io.Writer
+	l := &Logger{}
+	if err := l.Reconfigure(config); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
 
-	// Configure output destination
-	switch config.OutputPath {
-	case "stdout", "":
-		writer = os.Stdout
-	case "stderr":
-		writer = os.Stderr
-	default:
-		// Ensure directory exists
-		dir := filepath.Dir(config.OutputPath)
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return nil, fmt.Errorf("failed to create log directory: %w", err)
-		}
-		
-		file, err := os.OpenFile(config.OutputPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-		if err != nil {
-			return nil, fmt.Errorf("failed to open log file: %w", err)
-		}
-		writer = file
+// Reconfigure applies a new level, format and output without a restart. The
+// current output is kept when the path is unchanged and a log file opened for
+// the previous path is closed once the new output is in place.
+func (l *Logger) Reconfigure(config LogConfig) error {
+	outputPath := config.OutputPath
+	if outputPath == "" {
+		outputPath = "stdout"
 	}
 
 	// Default time format
@@ -142,21 +149,62 @@ func NewLogger(config LogConfig) (*Logger, error) {
 		format = FormatText
 	}
 
-	return &Logger{
-		level:      config.Level,
-		format:     format,
-		writer:     writer,
-		timeFormat: timeFormat,
-	}, nil
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.writer == nil || outputPath != l.outputPath {
+		writer, file, err := openOutput(outputPath)
+		if err != nil {
+			return err
+		}
+		if l.file != nil {
+			l.file.Close()
+		}
+		l.writer = writer
+		l.file = file
+		l.outputPath = outputPath
+	}
+
+	l.level = config.Level
+	l.format = format
+	l.timeFormat = timeFormat
+	return nil
+}
+
+// openOutput resolves a log output path to a writer. The file is returned
+// when one was opened so that it can be closed later.
+func openOutput(path string) (io.Writer, *os.File, error) {
+	switch path {
+	case "stdout":
+		return os.Stdout, nil, nil
+	case "stderr":
+		return os.Stderr, nil, nil
+	}
+
+	// Ensure directory exists
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	return file, file, nil
 }
 
 // SetWriter sets a new writer for the logger (useful for testing)
 func (l *Logger) SetWriter(writer io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
 	l.writer = writer
 }
 
-// log records a message at the specified level
-func (l *Logger) log(level LogLevel, message string, data map[string]any) {
+// log records a message at the specified level, correlated with the trace of ctx
+func (l *Logger) log(ctx context.Context, level LogLevel, message string, data map[string]any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
 	if level < l.level {
 		return
 	}
@@ -169,6 +217,10 @@ func (l *Logger) log(level LogLevel, message string, data map[string]any) {
 		Message: message,
 		Data:    data,
 	}
+	if span := tracing.SpanFromContext(ctx); span != nil {
+		entry.TraceID = span.Context.TraceID.String()
+		entry.SpanID = span.Context.SpanID.String()
+	}
 
 	var err error
 	var output []byte
@@ -190,10 +242,15 @@ func (l *Logger) log(level LogLevel, message string, data map[string]any) {
 			}
 		}
 
+		var trace string
+		if entry.TraceID != "" {
+			trace = fmt.Sprintf(" trace_id=%s span_id=%s", entry.TraceID, entry.SpanID)
+		}
+
 		if dataStr != "" {
-			output = []byte(fmt.Sprintf("[%s] %s - %s: %s\n", entry.Time, entry.Level, entry.Message, dataStr))
+			output = []byte(fmt.Sprintf("[%s] %s - %s: %s%s\n", entry.Time, entry.Level, entry.Message, dataStr, trace))
 		} else {
-			output = []byte(fmt.Sprintf("[%s] %s - %s\n", entry.Time, entry.Level, entry.Message))
+			output = []byte(fmt.Sprintf("[%s] %s - %s%s\n", entry.Time, entry.Level, entry.Message, trace))
 		}
 	}
 
@@ -218,7 +275,7 @@ func (l *Logger) Debug(message string, data ...map[string]any) {
 	if len(data) > 0 {
 		logData = data[0]
 	}
-	l.log(LevelDebug, message, logData)
+	l.log(context.Background(), LevelDebug, message, logData)
 }
 
 // Info logs an info message
@@ -227,7 +284,7 @@ func (l *Logger) Info(message string, data ...map[string]any) {
 	if len(data) > 0 {
 		logData = data[0]
 	}
-	l.log(LevelInfo, message, logData)
+	l.log(context.Background(), LevelInfo, message, logData)
 }
 
 // Warn logs a warning message
@@ -236,7 +293,7 @@ func (l *Logger) Warn(message string, data ...map[string]any) {
 	if len(data) > 0 {
 		logData = data[0]
 	}
-	l.log(LevelWarn, message, logData)
+	l.log(context.Background(), LevelWarn, message, logData)
 }
 
 // Error logs an error message
@@ -245,7 +302,7 @@ func (l *Logger) Error(message string, data ...map[string]any) {
 	if len(data) > 0 {
 		logData = data[0]
 	}
-	l.log(LevelError, message, logData)
+	l.log(context.Background(), LevelError, message, logData)
 }
 
 // Fatal logs a fatal error message and exits the program
@@ -254,7 +311,43 @@ func (l *Logger) Fatal(message string, data ...map[string]any) {
 	if len(data) > 0 {
 		logData = data[0]
 	}
-	l.log(LevelFatal, message, logData)
+	l.log(context.Background(), LevelFatal, message, logData)
+}
+
+// DebugContext logs a debug message with the trace ID of ctx
+func (l *Logger) DebugContext(ctx context.Context, message string, data ...map[string]any) {
+	var logData map[string]any
+	if len(data) > 0 {
+		logData = data[0]
+	}
+	l.log(ctx, LevelDebug, message, logData)
+}
+
+// InfoContext logs an info message with the trace ID of ctx
+func (l *Logger) InfoContext(ctx context.Context, message string, data ...map[string]any) {
+	var logData map[string]any
+	if len(data) > 0 {
+		logData = data[0]
+	}
+	l.log(ctx, LevelInfo, message, logData)
+}
+
+// WarnContext logs a warning message with the trace ID of ctx
+func (l *Logger) WarnContext(ctx context.Context, message string, data ...map[string]any) {
+	var logData map[string]any
+	if len(data) > 0 {
+		logData = data[0]
+	}
+	l.log(ctx, LevelWarn, message, logData)
+}
+
+// ErrorContext logs an error message with the trace ID of ctx
+func (l *Logger) ErrorContext(ctx context.Context, message string, data ...map[string]any) {
+	var logData map[string]any
+	if len(data) > 0 {
+		logData = data[0]
+	}
+	l.log(ctx, LevelError, message, logData)
 }
 
 // AccessLog records an HTTP request in the log
@@ -287,6 +380,9 @@ func (l *Logger) AccessLog(r *http.Request, status int, latency time.Duration) {
 	// Calculate latency in milliseconds
 	latencyMs := float64(latency.Microseconds()) / 1000.0
 
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
 	entry := AccessLogEntry{
 		Time:       time.Now().Format(l.timeFormat),
 		RemoteAddr: r.RemoteAddr,
@@ -299,6 +395,12 @@ func (l *Logger) AccessLog(r *http.Request, status int, latency time.Duration) {
 		Body:       reqBody,
 	}
 
+	// Correlate the access log with the request's trace
+	if span := tracing.SpanFromContext(r.Context()); span != nil {
+		entry.TraceID = span.Context.TraceID.String()
+		entry.SpanID = span.Context.SpanID.String()
+	}
+
 	var err error
 	var output []byte
 
@@ -309,8 +411,12 @@ func (l *Logger) AccessLog(r *http.Request, status int, latency time.Duration) {
 			output = append(output, '\n')
 		}
 	default: // FormatText
+		var trace string
+		if entry.TraceID != "" {
+			trace = fmt.Sprintf(" trace_id=%s span_id=%s", entry.TraceID, entry.SpanID)
+		}
 		output = []byte(fmt.Sprintf(
-			"[%s] %s - %s %s %s %d %.2fms %s\n",
+			"[%s] %s - %s %s %s %d %.2fms %s%s\n",
 			entry.Time,
 			entry.RemoteAddr,
 			entry.Method,
@@ -319,6 +425,7 @@ func (l *Logger) AccessLog(r *http.Request, status int, latency time.Duration) {
 			entry.Status,
 			entry.Latency,
 			entry.UserAgent,
+			trace,
 		))
 	}
 
@@ -334,6 +441,8 @@ func (l *Logger) AccessLog(r *http.Request, status int, latency time.Duration) {
 
 // Close closes the logger's file handle
 func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
 	if closer, ok := l.writer.(io.Closer); ok {
 		return closer.Close()
 	}
@@ -2,13 +2,17 @@ package logger
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/tkc/go-json-server/src/tracing"
 )
 
 func TestLogLevel_String(t *testing.T) {
@@ -213,3 +217,112 @@ func TestLogger_AccessLog(t *testing.T) {
 	assert.Equal(t, 150.0, accessEntry.Latency)
 	assert.Equal(t, "test-agent", accessEntry.UserAgent)
 }
+
+func TestLogger_AccessLogIncludesTraceIDs(t *testing.T) {
+	var buf bytes.Buffer
+	log := &Logger{
+		level:      LevelDebug,
+		format:     FormatJSON,
+		writer:     &buf,
+		timeFormat: time.RFC3339,
+	}
+
+	parent, err := tracing.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	assert.NoError(t, err)
+	span := tracing.NewTracer("", nil).Start("GET", tracing.SpanKindServer, parent)
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req = req.WithContext(tracing.ContextWithSpan(req.Context(), span))
+	log.AccessLog(req, 200, time.Millisecond)
+
+	var accessEntry AccessLogEntry
+	err = json.Unmarshal([]byte(strings.TrimSpace(buf.String())), &accessEntry)
+	assert.NoError(t, err)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", accessEntry.TraceID)
+	assert.Equal(t, span.Context.SpanID.String(), accessEntry.SpanID)
+
+	// Text format appends the IDs
+	buf.Reset()
+	log.format = FormatText
+	log.AccessLog(req, 200, time.Millisecond)
+	assert.Contains(t, buf.String(), "trace_id=4bf92f3577b34da6a3ce929d0e0e4736")
+}
+
+func TestLogger_ContextIncludesTraceIDs(t *testing.T) {
+	var buf bytes.Buffer
+	log := &Logger{
+		level:      LevelDebug,
+		format:     FormatJSON,
+		writer:     &buf,
+		timeFormat: time.RFC3339,
+	}
+
+	parent, err := tracing.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	assert.NoError(t, err)
+	span := tracing.NewTracer("", nil).Start("GET", tracing.SpanKindServer, parent)
+	ctx := tracing.ContextWithSpan(context.Background(), span)
+
+	log.ErrorContext(ctx, "failed", map[string]any{"path": "/users"})
+
+	var entry LogEntry
+	assert.NoError(t, json.Unmarshal([]byte(strings.TrimSpace(buf.String())), &entry))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", entry.TraceID)
+	assert.Equal(t, span.Context.SpanID.String(), entry.SpanID)
+	assert.Equal(t, "/users", entry.Data["path"])
+
+	// Text format appends the IDs
+	buf.Reset()
+	log.format = FormatText
+	log.InfoContext(ctx, "served")
+	assert.Contains(t, buf.String(), "served trace_id=4bf92f3577b34da6a3ce929d0e0e4736")
+
+	// Without a span nothing is added
+	buf.Reset()
+	log.InfoContext(context.Background(), "served")
+	assert.NotContains(t, buf.String(), "trace_id")
+}
+
+func TestLogger_Reconfigure(t *testing.T) {
+	tempDir := t.TempDir()
+	firstPath := filepath.Join(tempDir, "first.log")
+	secondPath := filepath.Join(tempDir, "logs", "second.log")
+
+	log, err := NewLogger(LogConfig{Level: LevelInfo, Format: FormatText, OutputPath: firstPath})
+	assert.NoError(t, err)
+	defer log.Close()
+
+	log.Debug("hidden")
+	log.Info("first")
+
+	// Level, format and output change together
+	err = log.Reconfigure(LogConfig{Level: LevelDebug, Format: FormatJSON, OutputPath: secondPath})
+	assert.NoError(t, err)
+	log.Debug("second")
+
+	first, err := os.ReadFile(firstPath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(first), "INFO - first")
+	assert.NotContains(t, string(first), "hidden")
+	assert.NotContains(t, string(first), "second")
+
+	second, err := os.ReadFile(secondPath)
+	assert.NoError(t, err)
+	var entry LogEntry
+	assert.NoError(t, json.Unmarshal(bytes.TrimSpace(second), &entry))
+	assert.Equal(t, "DEBUG", entry.Level)
+	assert.Equal(t, "second", entry.Message)
+
+	// The same path keeps the open file
+	file := log.file
+	err = log.Reconfigure(LogConfig{Level: LevelWarn, OutputPath: secondPath})
+	assert.NoError(t, err)
+	assert.Same(t, file, log.file)
+	assert.Equal(t, FormatText, log.format)
+
+	// A failed reconfigure keeps the previous output
+	blocker := filepath.Join(tempDir, "blocker")
+	assert.NoError(t, os.WriteFile(blocker, nil, 0644))
+	err = log.Reconfigure(LogConfig{OutputPath: filepath.Join(blocker, "x.log")})
+	assert.Error(t, err)
+	assert.Same(t, file, log.file)
+}
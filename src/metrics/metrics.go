@@ -0,0 +1,56 @@
+package metrics
+
+// Namespace prefixes all metric names exposed by the server
+const Namespace = "go_json_server"
+
+// UnmatchedEndpoint labels requests that did not match a configured endpoint
+const UnmatchedEndpoint = "none"
+
+// Metrics holds the metrics exposed by the server
+type Metrics struct {
+	Registry *Registry
+
+	RequestsTotal    *CounterVec
+	RequestDuration  *HistogramVec
+	RequestsInFlight *Gauge
+	CacheHits        *CounterVec
+	CacheMisses      *CounterVec
+	ConfigReloads    *CounterVec
+}
+
+// New creates the server metrics in a fresh registry
+func New() *Metrics {
+	r := NewRegistry()
+
+	return &Metrics{
+		Registry: r,
+		RequestsTotal: r.NewCounterVec(
+			Namespace+"_http_requests_total",
+			"Total number of HTTP requests by endpoint pattern, method and status.",
+			"endpoint", "method", "status",
+		),
+		RequestDuration: r.NewHistogramVec(
+			Namespace+"_http_request_duration_seconds",
+			"HTTP request latency by endpoint pattern, method and status.",
+			DefBuckets,
+			"endpoint", "method", "status",
+		),
+		RequestsInFlight: r.NewGauge(
+			Namespace+"_http_requests_in_flight",
+			"Number of HTTP requests currently being served.",
+		),
+		CacheHits: r.NewCounterVec(
+			Namespace+"_cache_hits_total",
+			"Total number of response cache hits.",
+		),
+		CacheMisses: r.NewCounterVec(
+			Namespace+"_cache_misses_total",
+			"Total number of response cache misses.",
+		),
+		ConfigReloads: r.NewCounterVec(
+			Namespace+"_config_reloads_total",
+			"Total number of configuration reloads by result.",
+			"result",
+		),
+	}
+}
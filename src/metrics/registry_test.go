@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_WriteText(t *testing.T) {
+	r := NewRegistry()
+
+	requests := r.NewCounterVec("requests_total", "Total requests.", "path", "code")
+	requests.Inc("/users", "200")
+	requests.Inc("/users", "200")
+	requests.Add(3, `/a"b`, "500")
+
+	inFlight := r.NewGauge("in_flight", "Requests in flight.")
+	inFlight.Inc()
+	inFlight.Inc()
+	inFlight.Dec()
+
+	latency := r.NewHistogramVec("latency_seconds", "Latency.", []float64{1, 0.1}, "path")
+	latency.Observe(0.05, "/users")
+	latency.Observe(0.5, "/users")
+	latency.Observe(2, "/users")
+
+	var b strings.Builder
+	assert.NoError(t, r.WriteText(&b))
+
+	expected := `# HELP requests_total Total requests.
+# TYPE requests_total counter
+requests_total{path="/a\"b",code="500"} 3
+requests_total{path="/users",code="200"} 2
+# HELP in_flight Requests in flight.
+# TYPE in_flight gauge
+in_flight 1
+# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{path="/users",le="0.1"} 1
+latency_seconds_bucket{path="/users",le="1"} 2
+latency_seconds_bucket{path="/users",le="+Inf"} 3
+latency_seconds_sum{path="/users"} 2.55
+latency_seconds_count{path="/users"} 3
+`
+	assert.Equal(t, expected, b.String())
+
+	assert.Equal(t, float64(2), requests.Value("/users", "200"))
+	assert.Equal(t, float64(0), requests.Value("/missing", "404"))
+	assert.Equal(t, uint64(3), latency.Count("/users"))
+}
+
+func TestRegistry_UnlabelledCounterStartsAtZero(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounterVec("hits_total", "Hits.")
+
+	var b strings.Builder
+	assert.NoError(t, r.WriteText(&b))
+	assert.Contains(t, b.String(), "\nhits_total 0\n")
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	m := New()
+	m.CacheHits.Inc()
+	m.ConfigReloads.Inc("failure")
+
+	w := httptest.NewRecorder()
+	m.Registry.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, ContentType, w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "go_json_server_cache_hits_total 1\n")
+	assert.Contains(t, w.Body.String(), "go_json_server_cache_misses_total 0\n")
+	assert.Contains(t, w.Body.String(), `go_json_server_config_reloads_total{result="failure"} 1`)
+}
+
+func TestRegistry_FuncMetrics(t *testing.T) {
+	r := NewRegistry()
+	size := 3.0
+	r.NewGaugeFunc("size", "Size.", func() float64 { return size })
+	r.NewCounterFunc("evictions_total", "Evictions.", func() float64 { return 7 })
+
+	size = 5
+	var b strings.Builder
+	assert.NoError(t, r.WriteText(&b))
+
+	expected := `# HELP size Size.
+# TYPE size gauge
+size 5
+# HELP evictions_total Evictions.
+# TYPE evictions_total counter
+evictions_total 7
+`
+	assert.Equal(t, expected, b.String())
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/tkc/go-json-server/src/config"
+	"github.com/tkc/go-json-server/src/internal/httputil"
+)
+
+// DefaultAPIKeyHeader is the header checked for API keys when none is configured
+const DefaultAPIKeyHeader = "X-API-Key"
+
+// TokenVerifier validates bearer tokens, such as those issued by the mock OIDC provider
+type TokenVerifier interface {
+	VerifyToken(token string) (map[string]any, error)
+}
+
+// Auth is a middleware that enforces basic auth, API key or bearer token protection
+func Auth(auth config.AuthConfig, verifier TokenVerifier) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if auth.Disabled || authorized(r, auth, verifier) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			realm := auth.Realm
+			if realm == "" {
+				realm = "restricted"
+			}
+			if len(auth.Basic) > 0 {
+				w.Header().Add("WWW-Authenticate", fmt.Sprintf("Basic realm=%q", realm))
+			}
+			if auth.Bearer {
+				w.Header().Add("WWW-Authenticate", fmt.Sprintf("Bearer realm=%q", realm))
+			}
+
+			status := auth.FailureStatus
+			if status == 0 {
+				status = http.StatusUnauthorized
+			}
+			body := auth.FailureBody
+			if body == "" {
+				body = `{"error": "unauthorized"}`
+			}
+
+			w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+			w.WriteHeader(status)
+			w.Write([]byte(body))
+		})
+	}
+}
+
+// authorized reports whether any configured scheme accepts the request
+func authorized(r *http.Request, auth config.AuthConfig, verifier TokenVerifier) bool {
+	if len(auth.Basic) > 0 {
+		if user, pass, ok := r.BasicAuth(); ok {
+			for _, u := range auth.Basic {
+				// Evaluate both comparisons to avoid leaking which one failed
+				userMatch := httputil.SecureCompare(u.Username, user)
+				passMatch := httputil.SecureCompare(u.Password, pass)
+				if userMatch && passMatch {
+					return true
+				}
+			}
+		}
+	}
+
+	if len(auth.APIKeys) > 0 {
+		var candidates []string
+		header := auth.APIKeyHeader
+		if header == "" && auth.APIKeyQuery == "" {
+			header = DefaultAPIKeyHeader
+		}
+		if header != "" {
+			if key := r.Header.Get(header); key != "" {
+				candidates = append(candidates, key)
+			}
+		}
+		if auth.APIKeyQuery != "" {
+			if key := r.URL.Query().Get(auth.APIKeyQuery); key != "" {
+				candidates = append(candidates, key)
+			}
+		}
+
+		for _, candidate := range candidates {
+			for _, key := range auth.APIKeys {
+				if httputil.SecureCompare(key, candidate) {
+					return true
+				}
+			}
+		}
+	}
+
+	if auth.Bearer && verifier != nil {
+		header := r.Header.Get("Authorization")
+		if len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+			if _, err := verifier.VerifyToken(strings.TrimSpace(header[7:])); err == nil {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tkc/go-json-server/src/config"
+)
+
+// stubVerifier accepts a single fixed token
+type stubVerifier struct {
+	token string
+}
+
+func (v stubVerifier) VerifyToken(token string) (map[string]any, error) {
+	if token != v.token {
+		return nil, errors.New("invalid token")
+	}
+	return map[string]any{"sub": "user"}, nil
+}
+
+func TestAuth_Middleware(t *testing.T) {
+	okHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+	})
+
+	tests := []struct {
+		name       string
+		auth       config.AuthConfig
+		setup      func(r *http.Request)
+		target     string
+		wantStatus int
+	}{
+		{
+			name:       "Basic auth accepted",
+			auth:       config.AuthConfig{Basic: []config.BasicAuthUser{{Username: "admin", Password: "secret"}}},
+			setup:      func(r *http.Request) { r.SetBasicAuth("admin", "secret") },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Basic auth wrong password",
+			auth:       config.AuthConfig{Basic: []config.BasicAuthUser{{Username: "admin", Password: "secret"}}},
+			setup:      func(r *http.Request) { r.SetBasicAuth("admin", "wrong") },
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "API key in default header",
+			auth:       config.AuthConfig{APIKeys: []string{"key-1"}},
+			setup:      func(r *http.Request) { r.Header.Set("X-API-Key", "key-1") },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "API key in custom header",
+			auth:       config.AuthConfig{APIKeys: []string{"key-1"}, APIKeyHeader: "X-Token"},
+			setup:      func(r *http.Request) { r.Header.Set("X-API-Key", "key-1") },
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "API key in query",
+			auth:       config.AuthConfig{APIKeys: []string{"key-1"}, APIKeyQuery: "api_key"},
+			target:     "/test?api_key=key-1",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Bearer token accepted",
+			auth:       config.AuthConfig{Bearer: true},
+			setup:      func(r *http.Request) { r.Header.Set("Authorization", "Bearer good") },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Bearer token rejected",
+			auth:       config.AuthConfig{Bearer: true},
+			setup:      func(r *http.Request) { r.Header.Set("Authorization", "Bearer bad") },
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "Any scheme is enough",
+			auth:       config.AuthConfig{Basic: []config.BasicAuthUser{{Username: "admin", Password: "secret"}}, APIKeys: []string{"key-1"}},
+			setup:      func(r *http.Request) { r.Header.Set("X-API-Key", "key-1") },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Custom failure status",
+			auth:       config.AuthConfig{APIKeys: []string{"key-1"}, FailureStatus: http.StatusForbidden},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "Disabled",
+			auth:       config.AuthConfig{Disabled: true, APIKeys: []string{"key-1"}},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := tt.target
+			if target == "" {
+				target = "/test"
+			}
+			req := httptest.NewRequest("GET", target, nil)
+			if tt.setup != nil {
+				tt.setup(req)
+			}
+			w := httptest.NewRecorder()
+
+			Auth(tt.auth, stubVerifier{token: "good"})(okHandler).ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestAuth_FailureResponse(t *testing.T) {
+	okHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	auth := config.AuthConfig{
+		Realm:       "mock",
+		Basic:       []config.BasicAuthUser{{Username: "admin", Password: "secret"}},
+		FailureBody: `{"message":"denied"}`,
+	}
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	w := httptest.NewRecorder()
+	Auth(auth, nil)(okHandler).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, `Basic realm="mock"`, w.Header().Get("WWW-Authenticate"))
+	assert.Equal(t, `{"message":"denied"}`, w.Body.String())
+}
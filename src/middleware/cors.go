@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/tkc/go-json-server/src/config"
+)
+
+// CORSResolver returns the CORS policy that applies to a request, nil when
+// cross-origin requests are not allowed, and the methods its path supports
+type CORSResolver func(r *http.Request) (policy *config.CORSConfig, methods []string)
+
+// CORSPolicy is a middleware that applies the CORS policy resolved for each request.
+// Preflight requests are passed on so that the handler answers them with the
+// methods it actually supports, unless the policy simulates a preflight failure.
+// Requests whose path starts with one of skipPrefixes never get CORS headers.
+func CORSPolicy(resolve CORSResolver, skipPrefixes ...string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, prefix := range skipPrefixes {
+				if strings.HasPrefix(r.URL.Path, prefix) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			origin := r.Header.Get("Origin")
+			policy, methods := resolve(r)
+			if policy == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Responses differ by origin unless every origin gets "*"
+			w.Header().Add("Vary", "Origin")
+			if !policy.AllowsOrigin(origin) {
+				// Without CORS headers the browser blocks the response
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			if preflight && policy.Failure == config.CORSFailurePreflight {
+				status := policy.FailureStatus
+				if status == 0 {
+					status = http.StatusForbidden
+				}
+				w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+				w.WriteHeader(status)
+				w.Write([]byte(`{"error": "CORS preflight rejected"}`))
+				return
+			}
+
+			setCORSHeaders(w.Header(), r, policy, methods, preflight)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// setCORSHeaders writes the headers that allow a request from an accepted origin
+func setCORSHeaders(header http.Header, r *http.Request, policy *config.CORSConfig, methods []string, preflight bool) {
+	origin := r.Header.Get("Origin")
+	allowOrigin := origin
+	if policy.AllowsAnyOrigin() && !policy.AllowCredentials {
+		allowOrigin = "*"
+	}
+	credentials := policy.AllowCredentials
+
+	switch policy.Failure {
+	case config.CORSFailureMissingOrigin:
+		allowOrigin = ""
+	case config.CORSFailureWrongOrigin:
+		allowOrigin = config.CORSWrongOrigin
+	case config.CORSFailureWildcardCredentials:
+		allowOrigin, credentials = "*", true
+	}
+
+	if allowOrigin != "" {
+		header.Set("Access-Control-Allow-Origin", allowOrigin)
+	}
+	if credentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+
+	if !preflight {
+		if len(policy.ExposeHeaders) > 0 {
+			header.Set("Access-Control-Expose-Headers", strings.Join(policy.ExposeHeaders, ", "))
+		}
+		return
+	}
+
+	allowMethods := policy.AllowMethods
+	if len(allowMethods) == 0 {
+		allowMethods = methods
+	}
+	if len(allowMethods) == 0 {
+		allowMethods = []string{r.Header.Get("Access-Control-Request-Method")}
+	}
+	header.Set("Access-Control-Allow-Methods", strings.Join(allowMethods, ", "))
+
+	if len(policy.AllowHeaders) > 0 {
+		header.Set("Access-Control-Allow-Headers", strings.Join(policy.AllowHeaders, ", "))
+	} else if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+		header.Set("Access-Control-Allow-Headers", requested)
+	}
+
+	if policy.MaxAge > 0 {
+		header.Set("Access-Control-Max-Age", strconv.Itoa(policy.MaxAge))
+	}
+}
@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tkc/go-json-server/src/config"
+)
+
+// corsHandler applies policy to a handler answering "test response"
+func corsHandler(policy *config.CORSConfig, methods ...string) http.Handler {
+	resolve := func(*http.Request) (*config.CORSConfig, []string) { return policy, methods }
+	return CORSPolicy(resolve)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("test response"))
+	}))
+}
+
+// corsRequest sends a request from origin, a preflight when requestMethod is set
+func corsRequest(handler http.Handler, origin, requestMethod string) *httptest.ResponseRecorder {
+	method := http.MethodGet
+	if requestMethod != "" {
+		method = http.MethodOptions
+	}
+	req := httptest.NewRequest(method, "/test", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	if requestMethod != "" {
+		req.Header.Set("Access-Control-Request-Method", requestMethod)
+		req.Header.Set("Access-Control-Request-Headers", "content-type, x-trace")
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestCORSPolicy_Middleware(t *testing.T) {
+	t.Run("default policy allows every origin without credentials", func(t *testing.T) {
+		defaults := config.DefaultCORS
+		handler := corsHandler(&defaults)
+
+		w := corsRequest(handler, "http://app.local", "")
+		assert.Equal(t, "test response", w.Body.String())
+		assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+		assert.Equal(t, "Origin", w.Header().Get("Vary"))
+
+		// Preflight requests reach the handler, which knows the allowed methods
+		w = corsRequest(handler, "http://app.local", "PUT")
+		assert.Equal(t, "test response", w.Body.String())
+		assert.Equal(t, "PUT", w.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "content-type, x-trace", w.Header().Get("Access-Control-Allow-Headers"))
+
+		// Requests without an Origin are not cross-origin
+		w = corsRequest(handler, "", "")
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("credentials reflect the origin", func(t *testing.T) {
+		w := corsRequest(corsHandler(&config.CORSConfig{AllowOrigins: []string{"*"}, AllowCredentials: true}), "http://app.local", "")
+		assert.Equal(t, "http://app.local", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+	})
+
+	t.Run("origin list and regex", func(t *testing.T) {
+		handler := corsHandler(&config.CORSConfig{
+			AllowOrigins:     []string{"https://app.example.com", "https://*.staging.example.com"},
+			AllowOriginRegex: `^http://localhost:\d+$`,
+			AllowHeaders:     []string{"Content-Type"},
+			ExposeHeaders:    []string{"X-Total-Count", "Link"},
+			MaxAge:           600,
+		}, "GET", "HEAD", "OPTIONS", "POST")
+
+		for _, origin := range []string{"https://app.example.com", "https://web.staging.example.com", "http://localhost:5173"} {
+			w := corsRequest(handler, origin, "")
+			assert.Equal(t, origin, w.Header().Get("Access-Control-Allow-Origin"), origin)
+			assert.Equal(t, "X-Total-Count, Link", w.Header().Get("Access-Control-Expose-Headers"))
+		}
+		for _, origin := range []string{"https://evil.example.com", "http://app.example.com", "https://staging.example.com", "http://localhost"} {
+			w := corsRequest(handler, origin, "")
+			assert.Equal(t, "test response", w.Body.String())
+			assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"), origin)
+		}
+
+		w := corsRequest(handler, "https://app.example.com", "POST")
+		assert.Equal(t, "GET, HEAD, OPTIONS, POST", w.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "Content-Type", w.Header().Get("Access-Control-Allow-Headers"))
+		assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+		assert.Empty(t, w.Header().Get("Access-Control-Expose-Headers"))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		w := corsRequest(corsHandler(nil), "http://app.local", "")
+		assert.Equal(t, "test response", w.Body.String())
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("simulated failures", func(t *testing.T) {
+		policy := func(failure string) *config.CORSConfig {
+			return &config.CORSConfig{AllowOrigins: []string{"*"}, Failure: failure}
+		}
+
+		w := corsRequest(corsHandler(policy(config.CORSFailureMissingOrigin)), "http://app.local", "")
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+
+		w = corsRequest(corsHandler(policy(config.CORSFailureWrongOrigin)), "http://app.local", "")
+		assert.Equal(t, config.CORSWrongOrigin, w.Header().Get("Access-Control-Allow-Origin"))
+
+		w = corsRequest(corsHandler(policy(config.CORSFailureWildcardCredentials)), "http://app.local", "")
+		assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+
+		handler := corsHandler(policy(config.CORSFailurePreflight))
+		w = corsRequest(handler, "http://app.local", "DELETE")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+		w = corsRequest(handler, "http://app.local", "")
+		assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	})
+}
+
+func TestCORSPolicy_SkipPrefixes(t *testing.T) {
+	policy := &config.CORSConfig{AllowOrigins: []string{"*"}, AllowCredentials: true}
+	resolve := func(*http.Request) (*config.CORSConfig, []string) { return policy, nil }
+	handler := CORSPolicy(resolve, "/__admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("test response"))
+	}))
+
+	for _, path := range []string{"/__admin/endpoints", "/test"} {
+		for _, method := range []string{http.MethodPost, http.MethodOptions} {
+			req := httptest.NewRequest(method, path, nil)
+			req.Header.Set("Origin", "http://evil.example")
+			req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if path == "/test" {
+				assert.Equal(t, "http://evil.example", w.Header().Get("Access-Control-Allow-Origin"))
+				continue
+			}
+			// Skipped paths never get CORS headers
+			for name := range w.Header() {
+				assert.NotContains(t, name, "Access-Control-", path+" "+method)
+			}
+			assert.Empty(t, w.Header().Get("Vary"))
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tkc/go-json-server/src/journal"
+)
+
+// maxJournalBody is the number of request body bytes kept per journal entry
+const maxJournalBody = 64 * 1024
+
+// Journal is a middleware that records requests and their responses in a journal.
+// Requests whose path starts with one of skipPrefixes are not recorded.
+func Journal(j *journal.Journal, skipPrefixes ...string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, prefix := range skipPrefixes {
+				if strings.HasPrefix(r.URL.Path, prefix) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			start := time.Now()
+			r, route := withRouteInfo(r)
+
+			// Capture the body while leaving it readable for the handler
+			var body []byte
+			truncated := false
+			if r.Body != nil {
+				data, err := io.ReadAll(io.LimitReader(r.Body, maxJournalBody+1))
+				if err == nil {
+					truncated = len(data) > maxJournalBody
+					if truncated {
+						body = data[:maxJournalBody]
+					} else {
+						body = data
+					}
+					r.Body = readCloser{io.MultiReader(bytes.NewReader(data), r.Body), r.Body}
+				}
+			}
+
+			rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(rw, r)
+
+			requestID, _ := r.Context().Value("requestID").(string)
+			endpointID, pattern := route.Get()
+
+			j.Record(journal.Entry{
+				RequestID:     requestID,
+				Time:          start,
+				RemoteAddr:    r.RemoteAddr,
+				Method:        r.Method,
+				Path:          r.URL.Path,
+				Query:         r.URL.RawQuery,
+				Headers:       r.Header.Clone(),
+				Body:          string(body),
+				BodyTruncated: truncated,
+				EndpointID:    endpointID,
+				Endpoint:      pattern,
+				Status:        rw.statusCode,
+				Latency:       float64(time.Since(start).Microseconds()) / 1000.0,
+			})
+		})
+	}
+}
+
+// readCloser combines a reader with the closer of the original body
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tkc/go-json-server/src/journal"
+)
+
+func TestJournal_Middleware(t *testing.T) {
+	j := journal.New(10)
+
+	var handlerBody string
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		handlerBody = string(body)
+		SetRoute(r, "create-user", "/users")
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	handler := Journal(j, "/__admin")(testHandler)
+
+	req := httptest.NewRequest("POST", "/users?debug=1", strings.NewReader(`{"name":"alice"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	// The handler still sees the full body
+	assert.Equal(t, `{"name":"alice"}`, handlerBody)
+
+	entries := j.Entries(journal.Filter{})
+	assert.Len(t, entries, 1)
+	entry := entries[0]
+	assert.Equal(t, "POST", entry.Method)
+	assert.Equal(t, "/users", entry.Path)
+	assert.Equal(t, "debug=1", entry.Query)
+	assert.Equal(t, `{"name":"alice"}`, entry.Body)
+	assert.Equal(t, "application/json", entry.Headers.Get("Content-Type"))
+	assert.Equal(t, "create-user", entry.EndpointID)
+	assert.Equal(t, "/users", entry.Endpoint)
+	assert.Equal(t, http.StatusCreated, entry.Status)
+
+	// Skipped prefixes are not recorded
+	req = httptest.NewRequest("GET", "/__admin/requests", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Len(t, j.Entries(journal.Filter{}), 1)
+}
+
+func TestJournal_TruncatesLargeBodies(t *testing.T) {
+	j := journal.New(10)
+
+	var received int
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = len(body)
+	})
+
+	large := strings.Repeat("x", maxJournalBody+100)
+	req := httptest.NewRequest("POST", "/upload", strings.NewReader(large))
+	Journal(j)(testHandler).ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, len(large), received)
+	entry := j.Entries(journal.Filter{})[0]
+	assert.True(t, entry.BodyTruncated)
+	assert.Len(t, entry.Body, maxJournalBody)
+}
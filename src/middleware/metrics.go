@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tkc/go-json-server/src/metrics"
+)
+
+// Metrics is a middleware that records request counts, latencies and
+// in-flight requests. Requests are labelled with the matched endpoint
+// pattern rather than the raw path to keep label cardinality bounded.
+func Metrics(m *metrics.Metrics) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			m.RequestsInFlight.Inc()
+			defer m.RequestsInFlight.Dec()
+
+			r, route := withRouteInfo(r)
+			rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+
+			next.ServeHTTP(rw, r)
+
+			_, pattern := route.Get()
+			if pattern == "" {
+				pattern = metrics.UnmatchedEndpoint
+			}
+			status := strconv.Itoa(rw.statusCode)
+
+			m.RequestsTotal.Inc(pattern, r.Method, status)
+			m.RequestDuration.Observe(time.Since(start).Seconds(), pattern, r.Method, status)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tkc/go-json-server/src/metrics"
+)
+
+func TestMetrics_Middleware(t *testing.T) {
+	m := metrics.New()
+
+	var inFlight float64
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inFlight = m.RequestsInFlight.Value()
+		if r.URL.Path == "/users/42" {
+			SetRoute(r, "get-user", "/users/:id")
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	handler := Metrics(m)(testHandler)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/42", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/42", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/nope", nil))
+
+	// Requests are labelled by pattern, not raw path
+	assert.Equal(t, float64(2), m.RequestsTotal.Value("/users/:id", "GET", "200"))
+	assert.Equal(t, float64(1), m.RequestsTotal.Value(metrics.UnmatchedEndpoint, "GET", "404"))
+	assert.Equal(t, uint64(2), m.RequestDuration.Count("/users/:id", "GET", "200"))
+
+	// The gauge counts the request being served and drops back afterwards
+	assert.Equal(t, float64(1), inFlight)
+	assert.Equal(t, float64(0), m.RequestsInFlight.Value())
+}
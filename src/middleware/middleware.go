@@ -7,6 +7,7 @@ import (
 	"runtime/debug"
 	"time"
 
+	"github.com/tkc/go-json-server/src/config"
 	"github.com/tkc/go-json-server/src/logger"
 )
 
@@ -32,25 +33,16 @@ func Logger(log *logger.Logger) Middleware {
 	}
 }
 
-// CORS is a middleware that adds CORS headers to responses
-func CORS() Middleware {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Set CORS headers
-			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Credentials", "true")
-			w.Header().Set("Access-Control-Allow-Headers", "Origin, X-Requested-With, Content-Type, Accept, Authorization")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
-
-			// Handle preflight requests
-			if r.Method == http.MethodOptions {
-				w.WriteHeader(http.StatusOK)
-				return
-			}
+// corsMethods are the methods allowed by CORS
+var corsMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"}
 
-			next.ServeHTTP(w, r)
-		})
-	}
+// CORS is a middleware that adds CORS headers to responses, allowing every
+// origin with config.DefaultCORS
+func CORS() Middleware {
+	policy := config.DefaultCORS
+	return CORSPolicy(func(*http.Request) (*config.CORSConfig, []string) {
+		return &policy, corsMethods
+	})
 }
 
 // Timeout is a middleware that adds a timeout to the request context
@@ -98,7 +90,7 @@ func Recovery(log *logger.Logger) Middleware {
 				if err := recover(); err != nil {
 					// Log panic details
 					stack := debug.Stack()
-					log.Error("Panic recovered", map[string]any{
+					log.ErrorContext(r.Context(), "Panic recovered", map[string]any{
 						"error":      err,
 						"stacktrace": string(stack),
 						"path":       r.URL.Path,
@@ -64,25 +64,30 @@ func TestCORS_Middleware(t *testing.T) {
 	// Apply the middleware
 	handler := CORS()(testHandler)
 
-	// Test regular request
+	// Test cross-origin request
 	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("Origin", "http://app.local")
 	w := httptest.NewRecorder()
 	handler.ServeHTTP(w, req)
 
 	// Check CORS headers
+	assert.Equal(t, "test response", w.Body.String())
 	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
-	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
-	assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "Content-Type")
-	assert.Contains(t, w.Header().Get("Access-Control-Allow-Methods"), "GET")
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
 
 	// Test preflight request
 	req = httptest.NewRequest("OPTIONS", "/test", nil)
+	req.Header.Set("Origin", "http://app.local")
+	req.Header.Set("Access-Control-Request-Method", "PUT")
+	req.Header.Set("Access-Control-Request-Headers", "Content-Type")
 	w = httptest.NewRecorder()
 	handler.ServeHTTP(w, req)
 
-	// Check response for OPTIONS
-	assert.Equal(t, http.StatusOK, w.Code)
-	assert.Equal(t, "", w.Body.String()) // Empty body for OPTIONS
+	// Check preflight headers
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "Content-Type", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.Contains(t, w.Header().Get("Access-Control-Allow-Methods"), "GET")
+	assert.Contains(t, w.Header().Get("Access-Control-Allow-Methods"), "PUT")
 }
 
 func TestTimeout_Middleware(t *testing.T) {
@@ -0,0 +1,233 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tkc/go-json-server/src/config"
+)
+
+// defaultRateLimitWindow is used when a policy does not set a window
+const defaultRateLimitWindow = 60 * time.Second
+
+// sweepInterval is how often idle client states are discarded
+const sweepInterval = time.Minute
+
+// rateState tracks the quota of a single client key
+type rateState struct {
+	// Fixed window: number of requests in the window starting at windowStart
+	count       int
+	windowStart time.Time
+
+	// Token bucket: available tokens as of lastRefill
+	tokens     float64
+	lastRefill time.Time
+}
+
+// rateDecision describes the outcome of a rate limit check
+type rateDecision struct {
+	allowed    bool
+	remaining  int
+	reset      time.Duration
+	retryAfter time.Duration
+}
+
+// RateLimiter enforces a rate limit policy per client key
+type RateLimiter struct {
+	policy    config.RateLimitConfig
+	window    time.Duration
+	mu        sync.Mutex
+	states    map[string]*rateState
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewRateLimiter creates a rate limiter for the given policy
+func NewRateLimiter(policy config.RateLimitConfig) *RateLimiter {
+	window := time.Duration(policy.Window) * time.Second
+	if window <= 0 {
+		window = defaultRateLimitWindow
+	}
+
+	return &RateLimiter{
+		policy: policy,
+		window: window,
+		states: make(map[string]*rateState),
+		now:    time.Now,
+	}
+}
+
+// Policy returns the policy enforced by the limiter
+func (rl *RateLimiter) Policy() config.RateLimitConfig {
+	return rl.policy
+}
+
+// allow consumes one request from the quota of the given key
+func (rl *RateLimiter) allow(key string) rateDecision {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.now()
+	rl.sweep(now)
+
+	state, ok := rl.states[key]
+	if !ok {
+		state = &rateState{
+			windowStart: now,
+			tokens:      float64(rl.policy.Limit),
+			lastRefill:  now,
+		}
+		rl.states[key] = state
+	}
+
+	if rl.policy.Algorithm == config.RateLimitTokenBucket {
+		return rl.takeToken(state, now)
+	}
+	return rl.countInWindow(state, now)
+}
+
+// countInWindow applies the fixed window algorithm
+func (rl *RateLimiter) countInWindow(state *rateState, now time.Time) rateDecision {
+	if now.Sub(state.windowStart) >= rl.window {
+		state.windowStart = now
+		state.count = 0
+	}
+
+	reset := state.windowStart.Add(rl.window).Sub(now)
+	if state.count >= rl.policy.Limit {
+		return rateDecision{allowed: false, remaining: 0, reset: reset, retryAfter: reset}
+	}
+
+	state.count++
+	return rateDecision{allowed: true, remaining: rl.policy.Limit - state.count, reset: reset}
+}
+
+// takeToken applies the token bucket algorithm
+func (rl *RateLimiter) takeToken(state *rateState, now time.Time) rateDecision {
+	limit := float64(rl.policy.Limit)
+	ratePerSecond := limit / rl.window.Seconds()
+
+	elapsed := now.Sub(state.lastRefill).Seconds()
+	state.tokens = math.Min(limit, state.tokens+elapsed*ratePerSecond)
+	state.lastRefill = now
+
+	if state.tokens < 1 {
+		retryAfter := time.Duration((1 - state.tokens) / ratePerSecond * float64(time.Second))
+		reset := time.Duration((limit - state.tokens) / ratePerSecond * float64(time.Second))
+		return rateDecision{allowed: false, remaining: 0, reset: reset, retryAfter: retryAfter}
+	}
+
+	state.tokens--
+	reset := time.Duration((limit - state.tokens) / ratePerSecond * float64(time.Second))
+	return rateDecision{allowed: true, remaining: int(state.tokens), reset: reset}
+}
+
+// Sweep discards the states of idle clients and returns how many clients remain
+func (rl *RateLimiter) Sweep() int {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.discardIdle(rl.now())
+	return len(rl.states)
+}
+
+// sweep discards idle client states at most once per sweepInterval
+func (rl *RateLimiter) sweep(now time.Time) {
+	if now.Sub(rl.lastSweep) < sweepInterval {
+		return
+	}
+	rl.discardIdle(now)
+}
+
+// discardIdle discards client states that have fully recovered their quota
+func (rl *RateLimiter) discardIdle(now time.Time) {
+	rl.lastSweep = now
+
+	for key, state := range rl.states {
+		if now.Sub(state.windowStart) >= rl.window && now.Sub(state.lastRefill) >= rl.window {
+			delete(rl.states, key)
+		}
+	}
+}
+
+// key derives the client key of a request according to the policy
+func (rl *RateLimiter) key(r *http.Request) string {
+	switch rl.policy.KeyBy {
+	case config.RateLimitKeyAPIKey:
+		header := rl.policy.KeyHeader
+		if header == "" {
+			header = DefaultAPIKeyHeader
+		}
+		if key := r.Header.Get(header); key != "" {
+			return "key:" + key
+		}
+	case config.RateLimitKeyHeader:
+		if value := r.Header.Get(rl.policy.KeyHeader); value != "" {
+			return "header:" + value
+		}
+	}
+
+	// Fall back to the client IP
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// RateLimitResolver returns the limiter that applies to a request, or nil when
+// the request is not rate limited
+type RateLimitResolver func(r *http.Request) *RateLimiter
+
+// RateLimit is a middleware that rejects requests exceeding the limiter's policy
+// with 429 Too Many Requests, advertising the quota through RateLimit-* headers
+func RateLimit(limiter *RateLimiter) Middleware {
+	return DynamicRateLimit(func(*http.Request) *RateLimiter { return limiter })
+}
+
+// DynamicRateLimit is like RateLimit with the limiter resolved per request,
+// so that policy changes apply without rebuilding the middleware chain
+func DynamicRateLimit(resolve RateLimitResolver) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limiter := resolve(r)
+			if limiter == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			decision := limiter.allow(limiter.key(r))
+
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(limiter.policy.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(decision.remaining))
+			w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(decision.reset)))
+
+			if !decision.allowed {
+				body := limiter.policy.FailureBody
+				if body == "" {
+					body = `{"error": "too many requests"}`
+				}
+
+				w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(decision.retryAfter)))
+				w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+				w.WriteHeader(http.StatusTooManyRequests)
+				w.Write([]byte(body))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ceilSeconds rounds a duration up to whole seconds, as used by the rate limit headers
+func ceilSeconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tkc/go-json-server/src/config"
+)
+
+// fakeClock is a manually advanced clock for rate limiter tests
+type fakeClock struct {
+	current time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.current }
+
+func (c *fakeClock) advance(d time.Duration) { c.current = c.current.Add(d) }
+
+func newTestLimiter(policy config.RateLimitConfig) (*RateLimiter, *fakeClock) {
+	clock := &fakeClock{current: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	limiter := NewRateLimiter(policy)
+	limiter.now = clock.now
+	return limiter, clock
+}
+
+func doLimited(handler http.Handler, setup func(r *http.Request)) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/test", nil)
+	if setup != nil {
+		setup(req)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimit_FixedWindow(t *testing.T) {
+	limiter, clock := newTestLimiter(config.RateLimitConfig{Limit: 2, Window: 10})
+	handler := RateLimit(limiter)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	w := doLimited(handler, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "10", w.Header().Get("RateLimit-Reset"))
+
+	clock.advance(4 * time.Second)
+	w = doLimited(handler, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+
+	w = doLimited(handler, nil)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "6", w.Header().Get("Retry-After"))
+	assert.Equal(t, "6", w.Header().Get("RateLimit-Reset"))
+	assert.Contains(t, w.Body.String(), "too many requests")
+
+	// A new window restores the quota
+	clock.advance(6 * time.Second)
+	w = doLimited(handler, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+}
+
+func TestRateLimit_TokenBucket(t *testing.T) {
+	limiter, clock := newTestLimiter(config.RateLimitConfig{
+		Algorithm: config.RateLimitTokenBucket,
+		Limit:     2,
+		Window:    10,
+	})
+	handler := RateLimit(limiter)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	assert.Equal(t, http.StatusOK, doLimited(handler, nil).Code)
+	assert.Equal(t, http.StatusOK, doLimited(handler, nil).Code)
+
+	w := doLimited(handler, nil)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	// One token refills every 5 seconds
+	assert.Equal(t, "5", w.Header().Get("Retry-After"))
+	assert.Equal(t, "10", w.Header().Get("RateLimit-Reset"))
+
+	clock.advance(5 * time.Second)
+	w = doLimited(handler, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+}
+
+func TestRateLimit_Keys(t *testing.T) {
+	okHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	t.Run("By IP", func(t *testing.T) {
+		limiter, _ := newTestLimiter(config.RateLimitConfig{Limit: 1})
+		handler := RateLimit(limiter)(okHandler)
+
+		assert.Equal(t, http.StatusOK, doLimited(handler, func(r *http.Request) { r.RemoteAddr = "10.0.0.1:1234" }).Code)
+		assert.Equal(t, http.StatusTooManyRequests, doLimited(handler, func(r *http.Request) { r.RemoteAddr = "10.0.0.1:5678" }).Code)
+		assert.Equal(t, http.StatusOK, doLimited(handler, func(r *http.Request) { r.RemoteAddr = "10.0.0.2:1234" }).Code)
+	})
+
+	t.Run("By API key", func(t *testing.T) {
+		limiter, _ := newTestLimiter(config.RateLimitConfig{Limit: 1, KeyBy: config.RateLimitKeyAPIKey})
+		handler := RateLimit(limiter)(okHandler)
+
+		assert.Equal(t, http.StatusOK, doLimited(handler, func(r *http.Request) { r.Header.Set("X-API-Key", "a") }).Code)
+		assert.Equal(t, http.StatusTooManyRequests, doLimited(handler, func(r *http.Request) { r.Header.Set("X-API-Key", "a") }).Code)
+		assert.Equal(t, http.StatusOK, doLimited(handler, func(r *http.Request) { r.Header.Set("X-API-Key", "b") }).Code)
+	})
+
+	t.Run("By header", func(t *testing.T) {
+		limiter, _ := newTestLimiter(config.RateLimitConfig{Limit: 1, KeyBy: config.RateLimitKeyHeader, KeyHeader: "X-Tenant"})
+		handler := RateLimit(limiter)(okHandler)
+
+		assert.Equal(t, http.StatusOK, doLimited(handler, func(r *http.Request) { r.Header.Set("X-Tenant", "t1") }).Code)
+		assert.Equal(t, http.StatusOK, doLimited(handler, func(r *http.Request) { r.Header.Set("X-Tenant", "t2") }).Code)
+		assert.Equal(t, http.StatusTooManyRequests, doLimited(handler, func(r *http.Request) { r.Header.Set("X-Tenant", "t1") }).Code)
+	})
+}
+
+func TestRateLimiter_Sweep(t *testing.T) {
+	limiter, clock := newTestLimiter(config.RateLimitConfig{Limit: 1, Window: 1})
+
+	limiter.allow("a")
+	limiter.allow("b")
+	assert.Len(t, limiter.states, 2)
+
+	clock.advance(2 * time.Minute)
+	limiter.allow("c")
+	assert.Len(t, limiter.states, 1)
+}
+
+func TestRateLimiter_SweepIdle(t *testing.T) {
+	limiter, clock := newTestLimiter(config.RateLimitConfig{Limit: 1, Window: 10})
+
+	limiter.allow("a")
+	clock.advance(5 * time.Second)
+	limiter.allow("b")
+	assert.Equal(t, 2, limiter.Sweep())
+
+	// Only clients whose quota has recovered are discarded
+	clock.advance(6 * time.Second)
+	assert.Equal(t, 1, limiter.Sweep())
+	clock.advance(5 * time.Second)
+	assert.Equal(t, 0, limiter.Sweep())
+}
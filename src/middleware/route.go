@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// routeInfoKey is the context key for the matched route of a request
+type routeInfoKey struct{}
+
+// RouteInfo carries the endpoint matched by the handler back to outer middlewares
+type RouteInfo struct {
+	mu         sync.Mutex
+	endpointID string
+	pattern    string
+}
+
+// Set records the matched endpoint
+func (ri *RouteInfo) Set(endpointID, pattern string) {
+	ri.mu.Lock()
+	defer ri.mu.Unlock()
+	ri.endpointID = endpointID
+	ri.pattern = pattern
+}
+
+// Get returns the matched endpoint ID and path pattern, empty if nothing matched
+func (ri *RouteInfo) Get() (endpointID, pattern string) {
+	ri.mu.Lock()
+	defer ri.mu.Unlock()
+	return ri.endpointID, ri.pattern
+}
+
+// withRouteInfo returns a request carrying a RouteInfo, reusing one set by an outer middleware
+func withRouteInfo(r *http.Request) (*http.Request, *RouteInfo) {
+	if ri, ok := r.Context().Value(routeInfoKey{}).(*RouteInfo); ok {
+		return r, ri
+	}
+	ri := &RouteInfo{}
+	return r.WithContext(context.WithValue(r.Context(), routeInfoKey{}, ri)), ri
+}
+
+// SetRoute records the endpoint matched for a request so that middlewares
+// can report it. It is a no-op when no middleware tracks the route.
+func SetRoute(r *http.Request, endpointID, pattern string) {
+	if ri, ok := r.Context().Value(routeInfoKey{}).(*RouteInfo); ok {
+		ri.Set(endpointID, pattern)
+	}
+}
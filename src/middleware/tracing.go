@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/tkc/go-json-server/src/tracing"
+)
+
+// maxTracestateLength is the longest tracestate value that is propagated
+const maxTracestateLength = 512
+
+// Tracing is a middleware that continues the W3C trace context of incoming
+// requests, records a server span for each request and returns the span
+// context in the traceparent and tracestate response headers.
+func Tracing(tracer *tracing.Tracer) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			parent, err := tracing.ParseTraceparent(r.Header.Get(tracing.TraceparentHeader))
+			if err == nil {
+				if state := r.Header.Get(tracing.TracestateHeader); len(state) <= maxTracestateLength {
+					parent.TraceState = state
+				}
+			}
+
+			span := tracer.Start(r.Method, tracing.SpanKindServer, parent)
+			span.SetAttribute("http.request.method", r.Method)
+			span.SetAttribute("url.path", r.URL.Path)
+			span.SetAttribute("client.address", r.RemoteAddr)
+			if r.URL.RawQuery != "" {
+				span.SetAttribute("url.query", r.URL.RawQuery)
+			}
+			if ua := r.UserAgent(); ua != "" {
+				span.SetAttribute("user_agent.original", ua)
+			}
+			if r.Host != "" {
+				span.SetAttribute("server.address", r.Host)
+			}
+
+			w.Header().Set(tracing.TraceparentHeader, span.Context.Traceparent())
+			if span.Context.TraceState != "" {
+				w.Header().Set(tracing.TracestateHeader, span.Context.TraceState)
+			}
+
+			r, route := withRouteInfo(r.WithContext(tracing.ContextWithSpan(r.Context(), span)))
+			rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+
+			next.ServeHTTP(rw, r)
+
+			if endpointID, pattern := route.Get(); pattern != "" {
+				span.Name = r.Method + " " + pattern
+				span.SetAttribute("http.route", pattern)
+				span.SetAttribute("endpoint.id", endpointID)
+			}
+			span.SetAttribute("http.response.status_code", rw.statusCode)
+			if rw.statusCode >= http.StatusInternalServerError {
+				span.StatusCode = tracing.StatusError
+			}
+
+			tracer.Finish(span)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tkc/go-json-server/src/tracing"
+)
+
+func TestTracing_Middleware(t *testing.T) {
+	tracer := tracing.NewTracer("", nil)
+
+	var span *tracing.Span
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		span = tracing.SpanFromContext(r.Context())
+		SetRoute(r, "get-user", "/users/:id")
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	handler := Tracing(tracer)(testHandler)
+
+	req := httptest.NewRequest("GET", "/users/42", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set("tracestate", "vendor=value")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	// The request span continues the incoming trace
+	assert.NotNil(t, span)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.Context.TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", span.ParentSpanID.String())
+	assert.Equal(t, "GET /users/:id", span.Name)
+	assert.Equal(t, tracing.StatusError, span.StatusCode)
+
+	attrs := span.Attributes()
+	assert.Equal(t, "/users/:id", attrs["http.route"])
+	assert.Equal(t, "get-user", attrs["endpoint.id"])
+	assert.Equal(t, "/users/42", attrs["url.path"])
+	assert.Equal(t, http.StatusInternalServerError, attrs["http.response.status_code"])
+
+	// The span context is returned to the caller
+	assert.Equal(t, span.Context.Traceparent(), w.Header().Get("traceparent"))
+	assert.Equal(t, "vendor=value", w.Header().Get("tracestate"))
+
+	// An invalid traceparent starts a new trace
+	req = httptest.NewRequest("GET", "/users/42", nil)
+	req.Header.Set("traceparent", "garbage")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.NotEqual(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.Context.TraceID.String())
+	assert.False(t, span.ParentSpanID.IsValid())
+}
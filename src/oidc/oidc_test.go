@@ -0,0 +1,321 @@
+package oidc
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tkc/go-json-server/src/config"
+	"github.com/tkc/go-json-server/src/logger"
+)
+
+func newTestProvider(t *testing.T) *Provider {
+	cfg := &config.Config{
+		OIDC: config.OIDCConfig{
+			Enabled:         true,
+			PathPrefix:      "/oidc",
+			AccessTokenTTL:  3600,
+			RefreshTokenTTL: 86400,
+			Clients: []config.OIDCClient{
+				{ClientID: "spa", RedirectURIs: []string{"http://localhost:8080/callback"}},
+				{ClientID: "backend", ClientSecret: "secret"},
+			},
+			Users: []config.OIDCUser{
+				{Username: "alice", Password: "password", Subject: "user-1", Claims: map[string]any{"email": "alice@example.com"}},
+			},
+		},
+	}
+
+	log, err := logger.NewLogger(logger.LogConfig{Level: logger.LevelError})
+	assert.NoError(t, err)
+
+	provider, err := NewProvider(cfg, log)
+	assert.NoError(t, err)
+	return provider
+}
+
+func decodeBody(t *testing.T, w *httptest.ResponseRecorder) map[string]any {
+	var body map[string]any
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return body
+}
+
+func TestProvider_Discovery(t *testing.T) {
+	provider := newTestProvider(t)
+
+	req := httptest.NewRequest("GET", "http://mock.local/oidc/.well-known/openid-configuration", nil)
+	w := httptest.NewRecorder()
+	provider.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := decodeBody(t, w)
+	assert.Equal(t, "http://mock.local/oidc", body["issuer"])
+	assert.Equal(t, "http://mock.local/oidc/token", body["token_endpoint"])
+	assert.Equal(t, "http://mock.local/oidc/jwks", body["jwks_uri"])
+
+	// JWKS exposes the signing key
+	req = httptest.NewRequest("GET", "/oidc/jwks", nil)
+	w = httptest.NewRecorder()
+	provider.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	keys := decodeBody(t, w)["keys"].([]any)
+	assert.Len(t, keys, 1)
+	assert.Equal(t, provider.key.kid, keys[0].(map[string]any)["kid"])
+}
+
+func TestProvider_Options(t *testing.T) {
+	provider := newTestProvider(t)
+
+	req := httptest.NewRequest("OPTIONS", "http://mock.local/oidc/token", nil)
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	w := httptest.NewRecorder()
+	provider.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "OPTIONS, POST", w.Header().Get("Allow"))
+	assert.Equal(t, "OPTIONS, POST", w.Header().Get("Access-Control-Allow-Methods"))
+}
+
+func TestProvider_AuthorizationCodeWithPKCE(t *testing.T) {
+	provider := newTestProvider(t)
+
+	verifier := "test-verifier-with-enough-entropy-1234567890"
+	sum := sha256.Sum256([]byte(verifier))
+	challenge := base64URLEncode(sum[:])
+
+	// Without credentials the login form is shown
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {"spa"},
+		"redirect_uri":          {"http://localhost:8080/callback"},
+		"scope":                 {"openid email"},
+		"state":                 {"xyz"},
+		"nonce":                 {"n-1"},
+		"code_challenge":        {challenge},
+		"code_challenge_method": {"S256"},
+	}
+	req := httptest.NewRequest("GET", "/oidc/authorize?"+query.Encode(), nil)
+	w := httptest.NewRecorder()
+	provider.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "<form")
+
+	// Wrong password re-renders the form
+	form := url.Values{}
+	for k, v := range query {
+		form[k] = v
+	}
+	form.Set("username", "alice")
+	form.Set("password", "wrong")
+	req = httptest.NewRequest("POST", "/oidc/authorize", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	provider.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Valid credentials redirect with a code
+	form.Set("password", "password")
+	req = httptest.NewRequest("POST", "/oidc/authorize", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	provider.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusFound, w.Code)
+
+	location, err := url.Parse(w.Header().Get("Location"))
+	assert.NoError(t, err)
+	assert.Equal(t, "xyz", location.Query().Get("state"))
+	code := location.Query().Get("code")
+	assert.NotEmpty(t, code)
+
+	// Wrong verifier is rejected and burns the code
+	tokenForm := url.Values{
+		"grant_type":    {"authorization_code"},
+		"client_id":     {"spa"},
+		"code":          {code},
+		"redirect_uri":  {"http://localhost:8080/callback"},
+		"code_verifier": {"wrong"},
+	}
+	w = postToken(provider, tokenForm, "", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "invalid_grant", decodeBody(t, w)["error"])
+
+	// A login hint only prefills the form
+	query.Set("login_hint", "alice")
+	req = httptest.NewRequest("GET", "/oidc/authorize?"+query.Encode(), nil)
+	w = httptest.NewRecorder()
+	provider.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `<input name="username" value="alice"`)
+	assert.Empty(t, w.Header().Get("Location"))
+
+	// With auto-login it signs in directly; request a fresh code and redeem it
+	provider.Config.OIDC.AutoLogin = true
+	req = httptest.NewRequest("GET", "/oidc/authorize?"+query.Encode(), nil)
+	w = httptest.NewRecorder()
+	provider.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusFound, w.Code)
+	location, _ = url.Parse(w.Header().Get("Location"))
+	tokenForm.Set("code", location.Query().Get("code"))
+	tokenForm.Set("code_verifier", verifier)
+
+	w = postToken(provider, tokenForm, "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+
+	tokens := decodeBody(t, w)
+	assert.Equal(t, "Bearer", tokens["token_type"])
+	assert.NotEmpty(t, tokens["refresh_token"])
+
+	idClaims, err := provider.VerifyToken(tokens["id_token"].(string))
+	assert.NoError(t, err)
+	assert.Equal(t, "user-1", idClaims["sub"])
+	assert.Equal(t, "spa", idClaims["aud"])
+	assert.Equal(t, "n-1", idClaims["nonce"])
+	assert.Equal(t, "alice@example.com", idClaims["email"])
+
+	// Userinfo returns the user's claims
+	req = httptest.NewRequest("GET", "/oidc/userinfo", nil)
+	req.Header.Set("Authorization", "Bearer "+tokens["access_token"].(string))
+	w = httptest.NewRecorder()
+	provider.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "alice@example.com", decodeBody(t, w)["email"])
+
+	// Refresh tokens rotate
+	refreshForm := url.Values{
+		"grant_type":    {"refresh_token"},
+		"client_id":     {"spa"},
+		"refresh_token": {tokens["refresh_token"].(string)},
+	}
+	w = postToken(provider, refreshForm, "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, tokens["refresh_token"], decodeBody(t, w)["refresh_token"])
+
+	w = postToken(provider, refreshForm, "", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestProvider_AuthorizeRejectsUnknownRedirect(t *testing.T) {
+	provider := newTestProvider(t)
+
+	query := url.Values{
+		"response_type": {"code"},
+		"client_id":     {"spa"},
+		"redirect_uri":  {"http://evil.example/callback"},
+	}
+	req := httptest.NewRequest("GET", "/oidc/authorize?"+query.Encode(), nil)
+	w := httptest.NewRecorder()
+	provider.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Empty(t, w.Header().Get("Location"))
+}
+
+func TestProvider_ClientCredentials(t *testing.T) {
+	provider := newTestProvider(t)
+
+	form := url.Values{"grant_type": {"client_credentials"}, "scope": {"read"}}
+
+	w := postToken(provider, form, "backend", "wrong")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "invalid_client", decodeBody(t, w)["error"])
+
+	w = postToken(provider, form, "backend", "secret")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	tokens := decodeBody(t, w)
+	assert.Nil(t, tokens["id_token"])
+	assert.Nil(t, tokens["refresh_token"])
+
+	claims, err := provider.VerifyToken(tokens["access_token"].(string))
+	assert.NoError(t, err)
+	assert.Equal(t, "backend", claims["sub"])
+	assert.Equal(t, "read", claims["scope"])
+
+	// Public clients cannot use this grant
+	form.Set("client_id", "spa")
+	w = postToken(provider, form, "", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestProvider_SweepsExpiredGrants(t *testing.T) {
+	provider := newTestProvider(t)
+	now := time.Now()
+
+	provider.codes["expired"] = authCode{expiration: now.Add(-time.Second)}
+	provider.codes["valid"] = authCode{expiration: now.Add(time.Minute)}
+	provider.refreshTokens["expired"] = refreshGrant{expiration: now.Add(-time.Second)}
+	provider.refreshTokens["valid"] = refreshGrant{expiration: now.Add(time.Hour)}
+
+	provider.sweep(now)
+	assert.Equal(t, []string{"valid"}, mapKeys(provider.codes))
+	assert.Equal(t, []string{"valid"}, mapKeys(provider.refreshTokens))
+
+	// Sweeps are spaced out by the sweep interval
+	provider.codes["late"] = authCode{expiration: now.Add(-time.Second)}
+	provider.sweep(now.Add(time.Second))
+	assert.Contains(t, provider.codes, "late")
+	provider.sweep(now.Add(sweepInterval))
+	assert.NotContains(t, provider.codes, "late")
+}
+
+// mapKeys returns the sorted keys of a map
+func mapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestSigningKey_Verify(t *testing.T) {
+	key, err := newSigningKey()
+	assert.NoError(t, err)
+
+	token, err := key.sign(map[string]any{"sub": "x", "exp": time.Now().Add(time.Minute).Unix()})
+	assert.NoError(t, err)
+
+	claims, err := key.verify(token)
+	assert.NoError(t, err)
+	assert.Equal(t, "x", claims["sub"])
+
+	// Tampered payload
+	parts := strings.Split(token, ".")
+	tampered := parts[0] + "." + base64URLEncode([]byte(`{"sub":"y"}`)) + "." + parts[2]
+	_, err = key.verify(tampered)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	// Expired token
+	expired, err := key.sign(map[string]any{"sub": "x", "exp": time.Now().Add(-time.Minute).Unix()})
+	assert.NoError(t, err)
+	_, err = key.verify(expired)
+	assert.ErrorIs(t, err, ErrTokenExpired)
+
+	// Token from another key
+	other, err := newSigningKey()
+	assert.NoError(t, err)
+	foreign, err := other.sign(map[string]any{"sub": "x"})
+	assert.NoError(t, err)
+	_, err = key.verify(foreign)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func postToken(provider *Provider, form url.Values, user, pass string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/oidc/token", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if user != "" {
+		req.SetBasicAuth(user, pass)
+	}
+	w := httptest.NewRecorder()
+	provider.ServeHTTP(w, req)
+	return w
+}
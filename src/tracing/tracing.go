@@ -0,0 +1,208 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SpanKind values from the OTLP specification
+const (
+	SpanKindInternal = 1
+	SpanKindServer   = 2
+)
+
+// Status codes from the OTLP specification
+const (
+	StatusUnset = 0
+	StatusOK    = 1
+	StatusError = 2
+)
+
+const (
+	// DefaultServiceName is reported as service.name when none is configured
+	DefaultServiceName = "go-json-server"
+
+	// queueSize bounds the spans waiting for export; extra spans are dropped
+	queueSize = 2048
+
+	// batchSize is the number of spans sent in one export call
+	batchSize = 256
+
+	// flushInterval is how often pending spans are exported
+	flushInterval = time.Second
+)
+
+// Span is a timed operation within a trace
+type Span struct {
+	Name         string
+	Kind         int
+	Context      SpanContext
+	ParentSpanID SpanID
+	Start        time.Time
+	End          time.Time
+	StatusCode   int
+	StatusMsg    string
+
+	mu         sync.Mutex
+	attributes map[string]any
+}
+
+// SetAttribute records a key/value attribute on the span
+func (s *Span) SetAttribute(key string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.attributes == nil {
+		s.attributes = make(map[string]any)
+	}
+	s.attributes[key] = value
+}
+
+// Attributes returns a copy of the span attributes
+func (s *Span) Attributes() map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	attrs := make(map[string]any, len(s.attributes))
+	for k, v := range s.attributes {
+		attrs[k] = v
+	}
+	return attrs
+}
+
+// Exporter sends finished spans to a tracing backend
+type Exporter interface {
+	Export(ctx context.Context, serviceName string, spans []*Span) error
+}
+
+// Tracer creates spans and exports them in the background
+type Tracer struct {
+	ServiceName string
+	// OnError is called when an export fails
+	OnError func(error)
+
+	exporter Exporter
+	queue    chan *Span
+	done     chan struct{}
+	flushed  chan struct{}
+	once     sync.Once
+}
+
+// NewTracer creates a tracer. With a nil exporter spans are still created and
+// propagated but never exported.
+func NewTracer(serviceName string, exporter Exporter) *Tracer {
+	if serviceName == "" {
+		serviceName = DefaultServiceName
+	}
+
+	t := &Tracer{
+		ServiceName: serviceName,
+		exporter:    exporter,
+		queue:       make(chan *Span, queueSize),
+		done:        make(chan struct{}),
+		flushed:     make(chan struct{}),
+	}
+	if exporter != nil {
+		go t.run()
+	} else {
+		close(t.flushed)
+	}
+	return t
+}
+
+// Start begins a span. It continues the trace of parent when it is valid and
+// starts a new sampled trace otherwise.
+func (t *Tracer) Start(name string, kind int, parent SpanContext) *Span {
+	span := &Span{
+		Name:  name,
+		Kind:  kind,
+		Start: time.Now(),
+	}
+
+	if parent.IsValid() {
+		span.Context = SpanContext{
+			TraceID:    parent.TraceID,
+			SpanID:     newSpanID(),
+			Flags:      parent.Flags,
+			TraceState: parent.TraceState,
+		}
+		span.ParentSpanID = parent.SpanID
+	} else {
+		span.Context = SpanContext{
+			TraceID: newTraceID(),
+			SpanID:  newSpanID(),
+			Flags:   FlagSampled,
+		}
+	}
+	return span
+}
+
+// Finish ends a span and queues it for export when it is sampled
+func (t *Tracer) Finish(span *Span) {
+	span.End = time.Now()
+	if t.exporter == nil || !span.Context.Sampled() {
+		return
+	}
+
+	select {
+	case <-t.done:
+	case t.queue <- span:
+	default:
+		// Drop spans rather than slow down requests when the exporter lags
+	}
+}
+
+// Shutdown exports the pending spans and stops the background exporter
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	t.once.Do(func() { close(t.done) })
+
+	select {
+	case <-t.flushed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run batches queued spans and exports them periodically
+func (t *Tracer) run() {
+	defer close(t.flushed)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := t.exporter.Export(ctx, t.ServiceName, batch); err != nil && t.OnError != nil {
+			t.OnError(err)
+		}
+		batch = make([]*Span, 0, batchSize)
+	}
+
+	for {
+		select {
+		case span := <-t.queue:
+			batch = append(batch, span)
+			if len(batch) >= batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-t.done:
+			// Drain what is already queued before the final export
+			for {
+				select {
+				case span := <-t.queue:
+					batch = append(batch, span)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	sc, err := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	assert.NoError(t, err)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", sc.SpanID.String())
+	assert.True(t, sc.Sampled())
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", sc.Traceparent())
+
+	// Future versions may carry extra fields
+	_, err = ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra")
+	assert.NoError(t, err)
+
+	invalid := []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1",
+	}
+	for _, value := range invalid {
+		_, err := ParseTraceparent(value)
+		assert.ErrorIs(t, err, ErrInvalidTraceparent, value)
+	}
+}
+
+func TestTracer_Start(t *testing.T) {
+	tracer := NewTracer("", nil)
+	assert.Equal(t, DefaultServiceName, tracer.ServiceName)
+
+	// Without a parent a new sampled trace is started
+	root := tracer.Start("GET", SpanKindServer, SpanContext{})
+	assert.True(t, root.Context.IsValid())
+	assert.True(t, root.Context.Sampled())
+	assert.False(t, root.ParentSpanID.IsValid())
+
+	// A child continues the parent's trace
+	parent, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	parent.TraceState = "vendor=value"
+	child := tracer.Start("GET", SpanKindServer, parent)
+	assert.Equal(t, parent.TraceID, child.Context.TraceID)
+	assert.Equal(t, parent.SpanID, child.ParentSpanID)
+	assert.NotEqual(t, parent.SpanID, child.Context.SpanID)
+	assert.Equal(t, "vendor=value", child.Context.TraceState)
+	assert.False(t, child.Context.Sampled())
+}
+
+func TestFileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces", "spans.jsonl")
+	exporter, err := NewFileExporter(path)
+	assert.NoError(t, err)
+
+	tracer := NewTracer("mock", exporter)
+	span := tracer.Start("GET /users/:id", SpanKindServer, SpanContext{})
+	span.SetAttribute("http.route", "/users/:id")
+	span.SetAttribute("http.response.status_code", 200)
+	tracer.Finish(span)
+
+	// Unsampled spans are not exported
+	parent, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	tracer.Finish(tracer.Start("GET", SpanKindServer, parent))
+
+	assert.NoError(t, tracer.Shutdown(context.Background()))
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+
+	var payload otlpRequest
+	assert.NoError(t, json.Unmarshal([]byte(strings.TrimSpace(string(data))), &payload))
+	assert.Len(t, payload.ResourceSpans, 1)
+	assert.Equal(t, "service.name", payload.ResourceSpans[0].Resource.Attributes[0].Key)
+	assert.Equal(t, "mock", *payload.ResourceSpans[0].Resource.Attributes[0].Value.StringValue)
+
+	spans := payload.ResourceSpans[0].ScopeSpans[0].Spans
+	assert.Len(t, spans, 1)
+	assert.Equal(t, span.Context.TraceID.String(), spans[0].TraceID)
+	assert.Equal(t, "GET /users/:id", spans[0].Name)
+	assert.Equal(t, SpanKindServer, spans[0].Kind)
+	assert.Equal(t, "http.response.status_code", spans[0].Attributes[0].Key)
+	assert.Equal(t, "200", *spans[0].Attributes[0].Value.IntValue)
+	assert.Equal(t, "/users/:id", *spans[0].Attributes[1].Value.StringValue)
+}
+
+func TestHTTPExporter(t *testing.T) {
+	var body []byte
+	var header http.Header
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		header = r.Header
+	}))
+	defer collector.Close()
+
+	exporter := NewHTTPExporter(collector.URL+"/v1/traces", map[string]string{"Authorization": "Bearer secret"})
+	tracer := NewTracer("", nil)
+	span := tracer.Start("GET", SpanKindServer, SpanContext{})
+
+	err := exporter.Export(context.Background(), "mock", []*Span{span})
+	assert.NoError(t, err)
+	assert.Equal(t, "application/json", header.Get("Content-Type"))
+	assert.Equal(t, "Bearer secret", header.Get("Authorization"))
+	assert.Contains(t, string(body), span.Context.TraceID.String())
+
+	// Collector errors are reported
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	err = NewHTTPExporter(failing.URL, nil).Export(context.Background(), "mock", []*Span{span})
+	assert.Error(t, err)
+}
@@ -0,0 +1,373 @@
+// go-json-server web UI. Talks to the admin API served next to it.
+(function () {
+  "use strict";
+
+  var ADMIN = "/__admin";
+  var POLL_INTERVAL = 2000;
+  var METHODS = ["GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"];
+
+  var state = {
+    endpoints: [],
+    selectedId: null,
+    lastRequestId: 0,
+    paused: false,
+    requests: []
+  };
+
+  var el = {
+    baseUrl: document.getElementById("base-url"),
+    list: document.getElementById("endpoint-list"),
+    filter: document.getElementById("endpoint-filter"),
+    refresh: document.getElementById("refresh-endpoints"),
+    detail: document.getElementById("detail"),
+    template: document.getElementById("detail-template"),
+    journalBody: document.getElementById("journal-body"),
+    journalStatus: document.getElementById("journal-status"),
+    journalDetail: document.getElementById("journal-detail"),
+    journalPause: document.getElementById("journal-pause"),
+    journalClear: document.getElementById("journal-clear")
+  };
+
+  // api calls the admin API and decodes JSON responses
+  function api(method, path, body) {
+    var options = { method: method, headers: {} };
+    if (body !== undefined) {
+      options.headers["Content-Type"] = "application/json";
+      options.body = JSON.stringify(body);
+    }
+    return fetch(ADMIN + path, options).then(function (res) {
+      if (res.status === 204) {
+        return null;
+      }
+      return res.json().then(function (data) {
+        if (!res.ok) {
+          throw new Error(data && data.error ? data.error : res.statusText);
+        }
+        return data;
+      });
+    });
+  }
+
+  function baseUrl() {
+    return el.baseUrl.value.replace(/\/+$/, "");
+  }
+
+  function methodLabel(ep) {
+    return ep.folder ? "STATIC" : (ep.method || "ANY");
+  }
+
+  // Endpoint list
+
+  function loadEndpoints() {
+    return api("GET", "/endpoints").then(function (endpoints) {
+      state.endpoints = endpoints || [];
+      renderEndpoints();
+      if (state.selectedId) {
+        var selected = findEndpoint(state.selectedId);
+        if (selected) {
+          renderDetail(selected);
+        }
+      }
+    }).catch(function (err) {
+      el.list.innerHTML = "";
+      var li = document.createElement("li");
+      li.className = "error";
+      li.textContent = "Failed to load endpoints: " + err.message;
+      el.list.appendChild(li);
+    });
+  }
+
+  function findEndpoint(id) {
+    for (var i = 0; i < state.endpoints.length; i++) {
+      if (state.endpoints[i].id === id) {
+        return state.endpoints[i];
+      }
+    }
+    return null;
+  }
+
+  function renderEndpoints() {
+    var query = el.filter.value.toLowerCase();
+    el.list.innerHTML = "";
+
+    state.endpoints.forEach(function (ep) {
+      var label = methodLabel(ep);
+      if (query && (label + " " + ep.path).toLowerCase().indexOf(query) === -1) {
+        return;
+      }
+
+      var li = document.createElement("li");
+      if (ep.id === state.selectedId) li.classList.add("selected");
+      if (ep.disabled) li.classList.add("disabled");
+
+      var method = document.createElement("span");
+      method.className = "method " + label;
+      method.textContent = label;
+
+      var path = document.createElement("span");
+      path.textContent = ep.path;
+
+      li.appendChild(method);
+      li.appendChild(path);
+      li.addEventListener("click", function () {
+        state.selectedId = ep.id;
+        renderEndpoints();
+        renderDetail(ep);
+      });
+      el.list.appendChild(li);
+    });
+  }
+
+  // Endpoint detail
+
+  function renderDetail(ep) {
+    var view = el.template.content.cloneNode(true);
+    var $ = function (selector) { return view.querySelector(selector); };
+    var label = methodLabel(ep);
+
+    $(".method").textContent = label;
+    $(".method").className = "method " + label;
+    $(".path").textContent = ep.path;
+
+    var enabled = $(".enabled");
+    enabled.checked = !ep.disabled;
+    enabled.addEventListener("change", function () {
+      api("POST", "/endpoints/" + encodeURIComponent(ep.id) + (enabled.checked ? "/enable" : "/disable"), {})
+        .then(loadEndpoints)
+        .catch(function (err) { alert(err.message); });
+    });
+
+    var meta = $(".meta");
+    addMeta(meta, "ID", ep.id);
+    if (ep.status) addMeta(meta, "Status", ep.status);
+    if (ep.jsonPath) addMeta(meta, "Response file", ep.jsonPath);
+    if (ep.body || ep.bodyBase64) addMeta(meta, "Response", "inline");
+    if (ep.contentType || ep.type) addMeta(meta, "Content type", ep.contentType || ep.type);
+    if (ep.download) addMeta(meta, "Download", ep.download);
+    if (ep.folder) addMeta(meta, "Folder", ep.folder);
+    if (ep.hosts && ep.hosts.length) addMeta(meta, "Hosts", ep.hosts.join(", "));
+    if (ep.auth && !ep.auth.disabled) addMeta(meta, "Auth", describeAuth(ep.auth));
+    if (ep.rateLimit) addMeta(meta, "Rate limit", ep.rateLimit.limit + " / " + (ep.rateLimit.window || 60) + "s");
+
+    var variantNames = Object.keys(ep.variants || {});
+    if (variantNames.length > 0) {
+      $(".variants").classList.remove("hidden");
+      var select = $(".variant-select");
+      addOption(select, "", "Default");
+      variantNames.sort().forEach(function (name) { addOption(select, name, name); });
+      select.value = ep.variant || "";
+      select.addEventListener("change", function () {
+        api("POST", "/endpoints/" + encodeURIComponent(ep.id) + "/variant", { variant: select.value })
+          .then(loadEndpoints)
+          .catch(function (err) { alert(err.message); });
+      });
+    }
+
+    if (!ep.folder) {
+      setupResponseEditor(view, ep);
+    }
+    setupTryIt(view, ep);
+
+    el.detail.innerHTML = "";
+    el.detail.appendChild(view);
+  }
+
+  function addMeta(meta, name, value) {
+    var dt = document.createElement("dt");
+    dt.textContent = name;
+    var dd = document.createElement("dd");
+    dd.textContent = value;
+    meta.appendChild(dt);
+    meta.appendChild(dd);
+  }
+
+  function addOption(select, value, text) {
+    var option = document.createElement("option");
+    option.value = value;
+    option.textContent = text;
+    select.appendChild(option);
+  }
+
+  function describeAuth(auth) {
+    var schemes = [];
+    if (auth.basic && auth.basic.length) schemes.push("basic");
+    if (auth.apiKeys && auth.apiKeys.length) schemes.push("API key");
+    if (auth.bearer) schemes.push("bearer");
+    return schemes.join(", ");
+  }
+
+  function setupResponseEditor(view, ep) {
+    var section = view.querySelector(".response");
+    var editor = view.querySelector(".response-editor");
+    var message = view.querySelector(".response-message");
+    var filePath = view.querySelector(".file-path");
+    var variant = ep.variant || "";
+    var query = variant ? "?variant=" + encodeURIComponent(variant) : "";
+
+    api("GET", "/endpoints/" + encodeURIComponent(ep.id) + "/response" + query).then(function (file) {
+      section.classList.remove("hidden");
+      filePath.textContent = file.path;
+      editor.value = file.content;
+    }).catch(function () {
+      // Endpoints without a response file have nothing to edit
+    });
+
+    view.querySelector(".response-format").addEventListener("click", function () {
+      try {
+        editor.value = JSON.stringify(JSON.parse(editor.value), null, 2) + "\n";
+        showMessage(message, "", "");
+      } catch (err) {
+        showMessage(message, "Invalid JSON: " + err.message, "error");
+      }
+    });
+
+    view.querySelector(".response-save").addEventListener("click", function () {
+      api("PUT", "/endpoints/" + encodeURIComponent(ep.id) + "/response", { variant: variant, content: editor.value })
+        .then(function () { showMessage(message, "Saved", "success"); })
+        .catch(function (err) { showMessage(message, err.message, "error"); });
+    });
+  }
+
+  function showMessage(node, text, kind) {
+    node.textContent = text;
+    node.className = "response-message " + kind;
+  }
+
+  function setupTryIt(view, ep) {
+    var method = view.querySelector(".try-method");
+    METHODS.forEach(function (m) { addOption(method, m, m); });
+    method.value = ep.method && METHODS.indexOf(ep.method) !== -1 ? ep.method : "GET";
+
+    var path = view.querySelector(".try-path");
+    path.value = ep.path;
+
+    var headers = view.querySelector(".try-headers");
+    var body = view.querySelector(".try-body");
+    var result = view.querySelector(".try-result");
+    var status = view.querySelector(".try-status");
+    var responseHeaders = view.querySelector(".try-response-headers");
+    var responseBody = view.querySelector(".try-response-body");
+
+    view.querySelector(".try-send").addEventListener("click", function () {
+      var options = { method: method.value, headers: parseHeaders(headers.value) };
+      if (body.value && method.value !== "GET" && method.value !== "HEAD") {
+        options.body = body.value;
+      }
+
+      var started = performance.now();
+      fetch(baseUrl() + path.value, options).then(function (res) {
+        var elapsed = Math.round(performance.now() - started);
+        var lines = [];
+        res.headers.forEach(function (value, name) { lines.push(name + ": " + value); });
+
+        return res.text().then(function (text) {
+          result.classList.remove("hidden");
+          status.textContent = res.status + " " + res.statusText + " in " + elapsed + " ms";
+          status.className = "try-status status-" + String(res.status).charAt(0);
+          responseHeaders.textContent = lines.sort().join("\n");
+          responseBody.textContent = prettyJSON(text);
+        });
+      }).catch(function (err) {
+        result.classList.remove("hidden");
+        status.textContent = "Request failed: " + err.message;
+        status.className = "try-status error";
+        responseHeaders.textContent = "";
+        responseBody.textContent = "";
+      });
+    });
+  }
+
+  function parseHeaders(text) {
+    var headers = {};
+    text.split("\n").forEach(function (line) {
+      var index = line.indexOf(":");
+      if (index > 0) {
+        headers[line.slice(0, index).trim()] = line.slice(index + 1).trim();
+      }
+    });
+    return headers;
+  }
+
+  function prettyJSON(text) {
+    try {
+      return JSON.stringify(JSON.parse(text), null, 2);
+    } catch (err) {
+      return text;
+    }
+  }
+
+  // Live request journal
+
+  function pollJournal() {
+    if (state.paused) {
+      return;
+    }
+    api("GET", "/requests?afterId=" + state.lastRequestId).then(function (entries) {
+      el.journalStatus.textContent = "";
+      (entries || []).forEach(function (entry) {
+        state.lastRequestId = Math.max(state.lastRequestId, entry.id);
+        state.requests.unshift(entry);
+        el.journalBody.insertBefore(journalRow(entry), el.journalBody.firstChild);
+      });
+
+      // Keep the table bounded
+      while (state.requests.length > 200) {
+        state.requests.pop();
+        el.journalBody.removeChild(el.journalBody.lastChild);
+      }
+    }).catch(function (err) {
+      el.journalStatus.textContent = "Journal unavailable: " + err.message;
+    });
+  }
+
+  function journalRow(entry) {
+    var tr = document.createElement("tr");
+    var time = new Date(entry.time);
+    [
+      time.toLocaleTimeString(),
+      entry.method,
+      entry.path + (entry.query ? "?" + entry.query : ""),
+      entry.status,
+      entry.latency_ms.toFixed(1)
+    ].forEach(function (value, i) {
+      var td = document.createElement("td");
+      td.textContent = value;
+      if (i === 2) td.className = "path";
+      if (i === 3) td.className = "status-" + String(entry.status).charAt(0);
+      tr.appendChild(td);
+    });
+
+    tr.addEventListener("click", function () {
+      el.journalDetail.classList.remove("hidden");
+      el.journalDetail.textContent = JSON.stringify(entry, null, 2);
+    });
+    return tr;
+  }
+
+  // Wiring
+
+  el.baseUrl.value = localStorage.getItem("go-json-server.baseUrl") || window.location.origin;
+  el.baseUrl.addEventListener("change", function () {
+    localStorage.setItem("go-json-server.baseUrl", baseUrl());
+  });
+
+  el.filter.addEventListener("input", renderEndpoints);
+  el.refresh.addEventListener("click", loadEndpoints);
+
+  el.journalPause.addEventListener("click", function () {
+    state.paused = !state.paused;
+    el.journalPause.textContent = state.paused ? "Resume" : "Pause";
+  });
+
+  el.journalClear.addEventListener("click", function () {
+    api("DELETE", "/requests").then(function () {
+      state.requests = [];
+      el.journalBody.innerHTML = "";
+      el.journalDetail.classList.add("hidden");
+    }).catch(function (err) { alert(err.message); });
+  });
+
+  loadEndpoints();
+  pollJournal();
+  setInterval(pollJournal, POLL_INTERVAL);
+})();
@@ -0,0 +1,34 @@
+package ui
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// PathPrefix is the path under which the web UI is served
+const PathPrefix = "/__ui"
+
+//go:embed static
+var static embed.FS
+
+// Handler returns a handler serving the embedded single-page UI under PathPrefix.
+// The UI talks to the admin API, so it must be mounted next to it.
+func Handler() http.Handler {
+	assets, err := fs.Sub(static, "static")
+	if err != nil {
+		// The embedded directory is part of the binary, so this cannot happen at runtime
+		panic(err)
+	}
+
+	fileServer := http.StripPrefix(PathPrefix, http.FileServer(http.FS(assets)))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == PathPrefix {
+			http.Redirect(w, r, PathPrefix+"/", http.StatusMovedPermanently)
+			return
+		}
+		w.Header().Set("Cache-Control", "no-cache")
+		fileServer.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,42 @@
+package ui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	handler := Handler()
+
+	// The bare prefix redirects to the index
+	req := httptest.NewRequest("GET", "/__ui", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+	assert.Equal(t, "/__ui/", w.Header().Get("Location"))
+
+	// Index page
+	req = httptest.NewRequest("GET", "/__ui/", nil)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, w.Body.String(), "go-json-server")
+	assert.Contains(t, w.Body.String(), "app.js")
+
+	// Assets
+	for _, asset := range []string{"/__ui/app.js", "/__ui/style.css"} {
+		req = httptest.NewRequest("GET", asset, nil)
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, asset)
+	}
+
+	req = httptest.NewRequest("GET", "/__ui/missing.js", nil)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}